@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genai"
+
+	"linkedinScraper/linkedin"
+	"transformer/analyzer"
+)
+
+// promptJobID matches the JobID lines the analyzer writes in the prompt.
+var promptJobID = regexp.MustCompile(`(?m)^JobID: (\S+)$`)
+
+// fakeLinkedIn serves the listings of searches, mapping keywords to job
+// IDs, and a posting for every job listed.
+func fakeLinkedIn(t *testing.T, searches map[string][]string) *linkedin.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if jid, ok := strings.CutPrefix(r.URL.Path, "/voyager/api/jobs/jobPostings/"); ok {
+			fmt.Fprintf(w, `{
+				"companyDetails": {"com.linkedin.voyager.deco.jobs.web.shared.WebJobPostingCompany": {"companyResolutionResult": {"name": "Company %[1]s"}}},
+				"description": {"text": "<p>We are looking for a backend developer with Go experience for the job number %[1]s.</p>"},
+				"title": "Developer %[1]s",
+				"workplaceTypes": ["urn:li:fs_workplaceType:2"],
+				"listedAt": 1735787045000
+			}`, jid)
+			return
+		}
+		if r.URL.Path != "/voyager/api/voyagerJobsDashJobCards" {
+			t.Errorf("unexpected LinkedIn request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		var ids []string
+		for keywords, searchIDs := range searches {
+			if strings.Contains(r.URL.Query().Get("query"), fmt.Sprintf("keywords:%q", keywords)) {
+				ids = searchIDs
+			}
+		}
+		if r.URL.Query().Get("start") != "0" {
+			ids = nil
+		}
+		urns := make([]string, len(ids))
+		for i, id := range ids {
+			urns[i] = fmt.Sprintf(`"urn:li:fsd_jobPostingCard:(%s,JOB_DETAILS)"`, id)
+		}
+		fmt.Fprintf(w, `{
+			"metadata": {"jobCardPrefetchQueries": [{"prefetchJobPostingCardUrns": [%s]}]},
+			"paging": {"total": %d, "start": %s, "count": %d}
+		}`, strings.Join(urns, ","), len(ids), r.URL.Query().Get("start"), len(ids))
+	}))
+	t.Cleanup(server.Close)
+
+	client := linkedin.NewClient(server.Client(), rate.NewLimiter(rate.Inf, 1), linkedin.NewTokenPool([]string{"test-token"}))
+	client.BaseURL = server.URL
+	return client
+}
+
+// fakeGemini is a Gemini API server answering every generateContent request
+// with an analysis of each job in the prompt.
+type fakeGemini struct {
+	// unknownID, when set, is the job ID of one more analysis added to
+	// every response, as a model misspelling an ID would.
+	unknownID string
+
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (g *fakeGemini) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Contents []struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	if !strings.HasSuffix(r.URL.Path, ":generateContent") || json.NewDecoder(r.Body).Decode(&req) != nil || len(req.Contents) == 0 {
+		http.Error(w, `{"error": {"code": 400, "message": "bad request"}}`, http.StatusBadRequest)
+		return
+	}
+
+	var ids []string
+	analyses := []map[string]any{}
+	for _, part := range req.Contents[0].Parts {
+		for _, match := range promptJobID.FindAllStringSubmatch(part.Text, -1) {
+			ids = append(ids, match[1])
+			analyses = append(analyses, map[string]any{
+				"job_id":               match[1],
+				"seniority":            "Senior",
+				"skills":               []string{"Go", "go "},
+				"onsite_hybrid_remote": "onsite",
+			})
+		}
+	}
+	if g.unknownID != "" {
+		analyses = append(analyses, map[string]any{"job_id": g.unknownID, "seniority": "Junior", "skills": []string{}, "onsite_hybrid_remote": "remote"})
+	}
+	g.mu.Lock()
+	g.batches = append(g.batches, ids)
+	g.mu.Unlock()
+
+	text, _ := json.Marshal(analyses)
+	json.NewEncoder(w).Encode(map[string]any{
+		"candidates": []map[string]any{{
+			"content":      map[string]any{"role": "model", "parts": []map[string]any{{"text": string(text)}}},
+			"finishReason": "STOP",
+		}},
+	})
+}
+
+// batchCount returns how many batches g analyzed.
+func (g *fakeGemini) batchCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.batches)
+}
+
+// newTestAnalyzer returns an Analyzer sending batches of up to maxJobs jobs
+// to a Gemini client of gemini.
+func newTestAnalyzer(t *testing.T, gemini *fakeGemini, maxJobs int) *analyzer.Analyzer {
+	t.Helper()
+
+	server := httptest.NewServer(gemini)
+	t.Cleanup(server.Close)
+
+	genaiClient, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  server.Client(),
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobAnalyzer := analyzer.New(genaiClient.Models)
+	jobAnalyzer.Limits.MaxJobs = maxJobs
+	return jobAnalyzer
+}
+
+func TestRun(t *testing.T) {
+	client := fakeLinkedIn(t, map[string][]string{
+		"golang": {"1", "2", "3"},
+		"java":   {"3", "4"},
+	})
+	gemini := &fakeGemini{}
+	jobAnalyzer := newTestAnalyzer(t, gemini, 2)
+
+	db, err := openSQLiteStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stats, err := run(ctx, cancel, client, jobAnalyzer, db, []string{"golang", "java"}, linkedin.GeoIDArgentina, 0)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if want := (runStats{fetched: 4, analyzed: 4}); stats != want {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+	if n := gemini.batchCount(); n != 2 {
+		t.Errorf("Gemini got %d batches, want 2 of up to 2 jobs", n)
+	}
+
+	rows, err := db.db.Query(`
+		SELECT j.job_id, j.company, a.model, a.analysis FROM jobs j
+		JOIN job_analyses a ON a.job_id = j.job_id
+		ORDER BY j.job_id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var jobIDs []string
+	for rows.Next() {
+		var jobID, company, model, analysisJSON string
+		if err := rows.Scan(&jobID, &company, &model, &analysisJSON); err != nil {
+			t.Fatal(err)
+		}
+		jobIDs = append(jobIDs, jobID)
+
+		if company != "Company "+jobID {
+			t.Errorf("job %s stored with company %q", jobID, company)
+		}
+		if model != jobAnalyzer.Model {
+			t.Errorf("job %s analyzed with model %q, want %q", jobID, model, jobAnalyzer.Model)
+		}
+		var analysis analyzer.JobAnalysis
+		if err := json.Unmarshal([]byte(analysisJSON), &analysis); err != nil {
+			t.Fatalf("invalid analysis of job %s: %v", jobID, err)
+		}
+		// LinkedIn's workplace type overrides the model's
+		if analysis.Seniority != "Senior" || len(analysis.Skills) != 1 || analysis.OnsiteHybridRemote != "remote" {
+			t.Errorf("job %s stored with analysis %+v", jobID, analysis)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1", "2", "3", "4"}; strings.Join(jobIDs, ",") != strings.Join(want, ",") {
+		t.Errorf("got analyzed jobs %v, want %v", jobIDs, want)
+	}
+
+	// A second run finds every job already analyzed with the same model
+	stats, err = run(ctx, cancel, client, jobAnalyzer, db, []string{"golang", "java"}, linkedin.GeoIDArgentina, 0)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if want := (runStats{fetched: 4, skipped: 4}); stats != want {
+		t.Errorf("got stats %+v on the second run, want %+v", stats, want)
+	}
+	if n := gemini.batchCount(); n != 2 {
+		t.Errorf("Gemini got %d batches after the second run, want no new ones", n)
+	}
+}
+
+func TestRunDropsAnalysesOfUnknownJobs(t *testing.T) {
+	client := fakeLinkedIn(t, map[string][]string{"golang": {"1", "2"}})
+	jobAnalyzer := newTestAnalyzer(t, &fakeGemini{unknownID: "999"}, 2)
+
+	db, err := openSQLiteStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stats, err := run(ctx, cancel, client, jobAnalyzer, db, []string{"golang"}, linkedin.GeoIDArgentina, 0)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if want := (runStats{fetched: 2, analyzed: 2}); stats != want {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+
+	ids, err := db.AnalyzedJobIDs(jobAnalyzer.Model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"1": true, "2": true}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got analyzed jobs %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"linkedinScraper/linkedin"
+	"transformer/analyzer"
+)
+
+// postgresStore is the Store of a Postgres database, with the schema of the
+// SQLite one using native timestamp and JSONB columns.
+type postgresStore struct {
+	db *sql.DB
+}
+
+func openPostgresStore(dsn string) (*postgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open Postgres database: %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to Postgres database: %v", err)
+	}
+
+	createTables := []string{
+		`CREATE TABLE IF NOT EXISTS jobs (
+			job_id TEXT PRIMARY KEY,
+			company TEXT NOT NULL,
+			description TEXT NOT NULL,
+			title TEXT NOT NULL,
+			posted_at TIMESTAMPTZ,
+			language TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS job_analyses (
+			job_id TEXT PRIMARY KEY REFERENCES jobs(job_id),
+			model TEXT NOT NULL,
+			analysis JSONB NOT NULL,
+			analyzed_at TIMESTAMPTZ NOT NULL,
+			min_years_experience INTEGER
+		)`,
+		// Databases created before the column was added
+		`ALTER TABLE job_analyses ADD COLUMN IF NOT EXISTS min_years_experience INTEGER`,
+	}
+	for _, query := range createTables {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("could not create table: %v", err)
+		}
+	}
+
+	return &postgresStore{db: db}, nil
+}
+
+func (s *postgresStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *postgresStore) SaveJob(job *linkedin.JobPosting) error {
+	_, err := s.db.Exec(`
+		INSERT INTO jobs (job_id, company, description, title, posted_at, language)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (job_id) DO NOTHING`,
+		job.JobID, job.Company, job.Description, job.Title, job.PostedAt, sql.NullString{String: job.Language, Valid: job.Language != ""})
+	if err != nil {
+		return fmt.Errorf("could not insert job '%s': %v", job.JobID, err)
+	}
+
+	return nil
+}
+
+func (s *postgresStore) SaveAnalyses(analyses []analyzer.JobAnalysis, model string) error {
+	return saveAnalyses(s.db, `
+		INSERT INTO job_analyses (job_id, model, analysis, analyzed_at, min_years_experience)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (job_id) DO UPDATE SET
+			model = excluded.model, analysis = excluded.analysis, analyzed_at = excluded.analyzed_at,
+			min_years_experience = excluded.min_years_experience`,
+		analyses, model, time.Now())
+}
+
+func (s *postgresStore) AnalyzedJobIDs(model string) (map[string]bool, error) {
+	return analyzedJobIDs(s.db, `SELECT job_id FROM job_analyses WHERE model = $1`, model)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestPostgresStore runs against the database of the POSTGRES_TEST_DSN
+// environment variable, and is skipped when it is not set. Its rows are
+// deleted once it finishes.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	store, err := openPostgresStore(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	prefix := fmt.Sprintf("test-%d-", time.Now().UnixNano())
+	t.Cleanup(func() {
+		store.db.Exec(`DELETE FROM job_analyses WHERE job_id LIKE $1`, prefix+"%")
+		store.db.Exec(`DELETE FROM jobs WHERE job_id LIKE $1`, prefix+"%")
+	})
+
+	testStore(t, store, prefix, prefix+"model")
+
+	var jobs int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM jobs WHERE job_id LIKE $1`, prefix+"%").Scan(&jobs); err != nil {
+		t.Fatal(err)
+	}
+	if jobs != 2 {
+		t.Errorf("got %d stored jobs, want 2 with the repeated one stored once", jobs)
+	}
+}
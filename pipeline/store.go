@@ -0,0 +1,127 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"linkedinScraper/linkedin"
+	"linkedinScraper/sqlitedb"
+	"transformer/analyzer"
+)
+
+// Store persists the scraped jobs and their analyses. Every implementation
+// has the same logical schema: a jobs table with the scraper's columns (a
+// subset of them in Postgres) and a job_analyses table with the analysis of
+// every job.
+type Store interface {
+	// SaveJob stores job unless a job with its ID is already stored.
+	SaveJob(job *linkedin.JobPosting) error
+	// SaveAnalyses stores the analyses made by model, replacing earlier
+	// ones of the same jobs.
+	SaveAnalyses(analyses []analyzer.JobAnalysis, model string) error
+	// AnalyzedJobIDs returns the IDs of the jobs with an analysis made by
+	// model.
+	AnalyzedJobIDs(model string) (map[string]bool, error)
+	Close() error
+}
+
+// sqliteStore is the Store of a SQLite database, with the scraper's schema so
+// that both can share a database.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(sqliteFile string) (*sqliteStore, error) {
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// SaveJob stores job the way the scraper does, with every column its
+// queries, cache and CSV export read.
+func (s *sqliteStore) SaveJob(job *linkedin.JobPosting) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := sqlitedb.SaveJob(tx, job); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %v", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) SaveAnalyses(analyses []analyzer.JobAnalysis, model string) error {
+	return saveAnalyses(s.db, `
+		INSERT OR REPLACE INTO job_analyses (job_id, model, analysis, analyzed_at, min_years_experience)
+		VALUES (?, ?, ?, ?, ?)`, analyses, model, time.Now().Format(time.RFC3339))
+}
+
+func (s *sqliteStore) AnalyzedJobIDs(model string) (map[string]bool, error) {
+	return analyzedJobIDs(s.db, `SELECT job_id FROM job_analyses WHERE model = ?`, model)
+}
+
+// saveAnalyses inserts every analysis with query, which takes the job ID,
+// model, JSON encoded analysis, analyzedAt and minimum years of experience, in
+// a single transaction.
+func saveAnalyses(db *sql.DB, query string, analyses []analyzer.JobAnalysis, model string, analyzedAt any) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, analysis := range analyses {
+		data, err := json.Marshal(analysis)
+		if err != nil {
+			return fmt.Errorf("could not encode analysis of job '%s': %v", analysis.JobID, err)
+		}
+
+		if _, err := tx.Exec(query, analysis.JobID, model, string(data), analyzedAt, analysis.MinYearsExperience); err != nil {
+			return fmt.Errorf("could not insert analysis of job '%s': %v", analysis.JobID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+// analyzedJobIDs returns the job IDs selected by query.
+func analyzedJobIDs(db *sql.DB, query string, args ...any) (map[string]bool, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query analyzed jobs: %v", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("could not read analyzed jobs: %v", err)
+		}
+		ids[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read analyzed jobs: %v", err)
+	}
+
+	return ids, nil
+}
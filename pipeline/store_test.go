@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"linkedinScraper/linkedin"
+	"linkedinScraper/sqlitedb"
+	"transformer/analyzer"
+)
+
+// testStore checks the Store contract on store, with jobs whose IDs start
+// with prefix and analyses made by model, so it can run against a database
+// with other data.
+func testStore(t *testing.T, store Store, prefix, model string) {
+	t.Helper()
+
+	postedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := &linkedin.JobPosting{JobID: linkedin.JobID(prefix + "1"), Company: "Acme", Title: "Backend Developer",
+		Description: "Go", PostedAt: &postedAt, Language: "en"}
+	second := &linkedin.JobPosting{JobID: linkedin.JobID(prefix + "2"), Company: "Globex", Title: "Data Engineer", Description: "Spark"}
+	for _, job := range []*linkedin.JobPosting{first, second, first} {
+		if err := store.SaveJob(job); err != nil {
+			t.Fatalf("SaveJob(%s): %v", job.JobID, err)
+		}
+	}
+
+	ids, err := store.AnalyzedJobIDs(model)
+	if err != nil {
+		t.Fatalf("AnalyzedJobIDs: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("got analyzed jobs %v before any analysis", ids)
+	}
+
+	years := 3
+	err = store.SaveAnalyses([]analyzer.JobAnalysis{
+		{JobID: prefix + "1", Seniority: "Junior", Skills: []string{"go"}},
+		{JobID: prefix + "2", Seniority: "Senior", Skills: []string{"spark"}, MinYearsExperience: &years},
+	}, "other-"+model)
+	if err != nil {
+		t.Fatalf("SaveAnalyses: %v", err)
+	}
+
+	// A new analysis replaces the one of another model
+	if err := store.SaveAnalyses([]analyzer.JobAnalysis{{JobID: prefix + "1", Seniority: "Senior", Skills: []string{"go"}}}, model); err != nil {
+		t.Fatalf("SaveAnalyses: %v", err)
+	}
+
+	ids, err = store.AnalyzedJobIDs(model)
+	if err != nil {
+		t.Fatalf("AnalyzedJobIDs: %v", err)
+	}
+	if want := map[string]bool{prefix + "1": true}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got jobs analyzed with %s %v, want %v", model, ids, want)
+	}
+
+	ids, err = store.AnalyzedJobIDs("other-" + model)
+	if err != nil {
+		t.Fatalf("AnalyzedJobIDs: %v", err)
+	}
+	if want := map[string]bool{prefix + "2": true}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got jobs analyzed with other-%s %v, want %v", model, ids, want)
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := openSQLiteStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	testStore(t, store, "", "test-model")
+
+	var jobs int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&jobs); err != nil {
+		t.Fatal(err)
+	}
+	if jobs != 2 {
+		t.Errorf("got %d stored jobs, want 2 with the repeated one stored once", jobs)
+	}
+
+	var minYears int
+	if err := store.db.QueryRow(`SELECT min_years_experience FROM job_analyses WHERE job_id = '2'`).Scan(&minYears); err != nil {
+		t.Fatal(err)
+	}
+	if minYears != 3 {
+		t.Errorf("got min_years_experience %d, want 3", minYears)
+	}
+}
+
+func TestSQLiteStoreSavesScraperColumns(t *testing.T) {
+	store, err := openSQLiteStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	fetchedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	min := 1000.0
+	job := &linkedin.JobPosting{JobID: "1", Company: " Acme  Corp", Title: "Sr. Backend Developer", Description: "Go",
+		EmploymentType: "Full-time", Salary: &linkedin.Salary{Min: &min, Currency: "USD"}, Location: "Buenos Aires, Argentina",
+		WorkplaceType: "hybrid", FetchedAt: &fetchedAt, NormalizedTitle: "Backend Developer", RoleFamily: "Backend"}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+
+	var company, employmentType, currency, location, workplaceType, storedFetchedAt, hash, normalizedTitle, roleFamily string
+	var salaryMin float64
+	err = store.db.QueryRow(`
+		SELECT c.company_name, j.employment_type, j.salary_min, j.salary_currency, j.location, j.workplace_type,
+			j.fetched_at, j.description_hash, j.normalized_title, j.role_family
+		FROM jobs j JOIN companies c ON c.company_id = j.company_id
+		WHERE j.job_id = '1'`).Scan(&company, &employmentType, &salaryMin, &currency, &location, &workplaceType,
+		&storedFetchedAt, &hash, &normalizedTitle, &roleFamily)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []any{company, employmentType, salaryMin, currency, location, workplaceType, storedFetchedAt, hash, normalizedTitle, roleFamily}
+	want := []any{"Acme Corp", "Full-time", 1000.0, "USD", "Buenos Aires, Argentina", "hybrid",
+		"2025-01-02T03:04:05Z", sqlitedb.DescriptionHash("Go"), "Backend Developer", "Backend"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got stored columns %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"linkedinScraper/linkedin"
+	"linkedinScraper/sqlitedb"
+)
+
+// jobCache serves the postings stored in the jobs table of a previous run's
+// SQLite output, so jobs fetched recently aren't requested from LinkedIn
+// again.
+type jobCache struct {
+	db  *sql.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+func openJobCache(sqliteFile string, ttl time.Duration) (*jobCache, error) {
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobCache{db: db, ttl: ttl, now: time.Now}, nil
+}
+
+func (c *jobCache) close() error {
+	return c.db.Close()
+}
+
+// lookup returns the stored posting of jid when it was fetched within the
+// cache TTL. Any error reading it is logged and treated as a miss, and a nil
+// cache always misses.
+func (c *jobCache) lookup(jid JobID) (*JobPosting, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	job, err := c.load(jid)
+	if err != nil {
+		log.Printf("could not read cached job %s: %v", jid, err)
+		return nil, false
+	}
+	if job == nil || job.FetchedAt == nil || c.now().Sub(*job.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return job, true
+}
+
+// load reads the posting of jid from the jobs table, or nil when it's not
+// there.
+func (c *jobCache) load(jid JobID) (*JobPosting, error) {
+	job := &JobPosting{JobID: jid}
+	var employmentType, salaryCurrency, salaryPeriod, postedAt, language, extra, dedupGroup, fetchedAt, location, workplaceType sql.NullString
+	var salaryMin, salaryMax sql.NullFloat64
+	err := c.db.QueryRow(`
+		SELECT company, description, title, employment_type, salary_min, salary_max, salary_currency,
+			salary_period, posted_at, language, extra, dedup_group, fetched_at, location, workplace_type
+		FROM jobs WHERE job_id = ?`, jid).Scan(
+		&job.Company, &job.Description, &job.Title, &employmentType, &salaryMin, &salaryMax, &salaryCurrency,
+		&salaryPeriod, &postedAt, &language, &extra, &dedupGroup, &fetchedAt, &location, &workplaceType)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.EmploymentType = employmentType.String
+	job.Language = language.String
+	job.DedupGroup = dedupGroup.String
+	job.Location = location.String
+	job.WorkplaceType = workplaceType.String
+
+	if salaryMin.Valid || salaryMax.Valid || salaryCurrency.Valid || salaryPeriod.Valid {
+		job.Salary = &linkedin.Salary{Currency: salaryCurrency.String, Period: salaryPeriod.String}
+		if salaryMin.Valid {
+			job.Salary.Min = &salaryMin.Float64
+		}
+		if salaryMax.Valid {
+			job.Salary.Max = &salaryMax.Float64
+		}
+	}
+
+	if job.PostedAt, err = parseTime(postedAt); err != nil {
+		return nil, fmt.Errorf("invalid posted_at: %v", err)
+	}
+	if job.FetchedAt, err = parseTime(fetchedAt); err != nil {
+		return nil, fmt.Errorf("invalid fetched_at: %v", err)
+	}
+
+	if extra.Valid {
+		if err := json.Unmarshal([]byte(extra.String), &job.Extra); err != nil {
+			return nil, fmt.Errorf("invalid extra fields: %v", err)
+		}
+	}
+
+	if job.JobFunctions, err = c.names(`
+		SELECT f.job_function_name FROM jobs_job_functions jf
+		JOIN job_functions f ON f.job_function_id = jf.job_function_id
+		WHERE jf.job_id = ? ORDER BY f.job_function_name`, jid); err != nil {
+		return nil, err
+	}
+	if job.LinkedInSkills, err = c.names(`
+		SELECT s.skill_name FROM jobs_linkedin_skills js
+		JOIN linkedin_skills s ON s.skill_id = js.skill_id
+		WHERE js.job_id = ? ORDER BY s.skill_name`, jid); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// names returns the single text column of the rows of query.
+func (c *jobCache) names(query string, args ...any) ([]string, error) {
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func parseTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid || s.String == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"linkedinScraper/linkedin"
+)
+
+func TestScrapeJobsReusesCachedJobs(t *testing.T) {
+	dir := t.TempDir()
+	sqliteFile := filepath.Join(dir, "jobs.db")
+	now := time.Now().UTC().Truncate(time.Second)
+	fresh, stale := now.Add(-time.Hour), now.Add(-48*time.Hour)
+	cached := []JobCategoryGroup{{Category: "backend", Searches: []SearchGroup{{SearchTerm: "golang", Jobs: []*JobPosting{
+		{JobID: "1", Company: "Acme", Title: "Cached Developer", Description: "Go", FetchedAt: &fresh},
+		{JobID: "2", Company: "Globex", Title: "Stale Developer", Description: "Go", FetchedAt: &stale},
+	}}}}}
+	if err := saveJobsToSQLite(cached, sqliteFile, now); err != nil {
+		t.Fatalf("saveJobsToSQLite: %v", err)
+	}
+
+	cache, err := openJobCache(sqliteFile, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("openJobCache: %v", err)
+	}
+	defer cache.close()
+
+	progress, err := openCheckpoint(filepath.Join(dir, "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	client := fakeLinkedIn(t, map[string][]string{"golang": {"1", "2", "3"}})
+	postings := countPostings(client, nil)
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang"}}}
+	jobGroups, _, err := scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		Progress:     progress,
+		Cache:        cache,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	if ids := jobIDs(jobGroups); !slices.Equal(ids, []JobID{"1", "2", "3"}) {
+		t.Fatalf("got jobs %q, want 1, 2 and 3", ids)
+	}
+	if want := map[JobID]int{"2": 1, "3": 1}; !reflect.DeepEqual(postings.requests, want) {
+		t.Errorf("requested postings %v, want only the stale and uncached jobs", postings.requests)
+	}
+	for _, job := range jobGroups[0].Searches[0].Jobs {
+		if job.JobID == "1" && job.Title != "Cached Developer" {
+			t.Errorf("cached job has title %q, want the stored one", job.Title)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"linkedinScraper/sqlitedb"
+)
+
+// JobChanges are the postings of a run that are new to the SQLite store or
+// whose description changed since it was stored, written with
+// --changes-only.
+type JobChanges struct {
+	New     []*JobPosting `json:"new"`
+	Changed []*JobPosting `json:"changed"`
+}
+
+// findChanges compares every job of jobGroups with the jobs stored in db,
+// which must be called before the run is saved to it.
+func findChanges(db *sql.DB, jobGroups []JobCategoryGroup) (JobChanges, error) {
+	changes := JobChanges{New: []*JobPosting{}, Changed: []*JobPosting{}}
+
+	seen := make(map[JobID]bool)
+	for _, jobGroup := range jobGroups {
+		for _, searchGroup := range jobGroup.Searches {
+			for _, job := range searchGroup.Jobs {
+				if seen[job.JobID] {
+					continue
+				}
+				seen[job.JobID] = true
+
+				// Jobs stored before description_hash was added are hashed
+				// from their description.
+				var description string
+				var hash sql.NullString
+				err := db.QueryRow(`SELECT description, description_hash FROM jobs WHERE job_id = ?`, job.JobID).Scan(&description, &hash)
+				if err == sql.ErrNoRows {
+					changes.New = append(changes.New, job)
+					continue
+				}
+				if err != nil {
+					return JobChanges{}, fmt.Errorf("could not read stored job '%s': %v", job.JobID, err)
+				}
+
+				if !hash.Valid {
+					hash.String = sqlitedb.DescriptionHash(description)
+				}
+				if hash.String != sqlitedb.DescriptionHash(job.Description) {
+					changes.Changed = append(changes.Changed, job)
+				}
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// saveJobChanges writes the changes of jobGroups against the SQLite store at
+// sqliteFile to path.
+func saveJobChanges(jobGroups []JobCategoryGroup, sqliteFile, path string, compact bool) (JobChanges, error) {
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		return JobChanges{}, err
+	}
+	defer db.Close()
+
+	changes, err := findChanges(db, jobGroups)
+	if err != nil {
+		return JobChanges{}, err
+	}
+
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		if !compact {
+			enc.SetIndent("", "  ")
+		}
+		if err := enc.Encode(changes); err != nil {
+			return fmt.Errorf("could not encode job changes: %v", err)
+		}
+		return nil
+	})
+	return changes, err
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveJobChanges(t *testing.T) {
+	dir := t.TempDir()
+	sqliteFile := filepath.Join(dir, "jobs.db")
+	if err := saveJobsToSQLite(testJobGroups(), sqliteFile, time.Now()); err != nil {
+		t.Fatalf("saveJobsToSQLite: %v", err)
+	}
+
+	// The second run finds job 1 with a new description, job 2 unchanged and
+	// job 3 for the first time.
+	jobGroups := testJobGroups()
+	jobGroups[0].Searches[0].Jobs[0].Description = "Go developer, now remote"
+	jobGroups[1].Searches[0].Jobs = append(jobGroups[1].Searches[0].Jobs, &JobPosting{JobID: "3", Company: "Initech", Title: "Data Analyst", Description: "SQL"})
+
+	path := filepath.Join(dir, "changes.json")
+	changes, err := saveJobChanges(jobGroups, sqliteFile, path, false)
+	if err != nil {
+		t.Fatalf("saveJobChanges: %v", err)
+	}
+
+	ids := func(jobs []*JobPosting) []JobID {
+		ids := []JobID{}
+		for _, job := range jobs {
+			ids = append(ids, job.JobID)
+		}
+		return ids
+	}
+	if got, want := ids(changes.New), []JobID{"3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got new jobs %q, want %q", got, want)
+	}
+	if got, want := ids(changes.Changed), []JobID{"1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got changed jobs %q, want %q", got, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded JobChanges
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("could not decode %s: %v", path, err)
+	}
+	if !reflect.DeepEqual(decoded, changes) {
+		t.Errorf("%s decodes to %+v, want %+v", path, decoded, changes)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpointEntry is one line of the checkpoint file: a job posting that was
+// already fetched for a given category and search term.
+type checkpointEntry struct {
+	Category   string      `json:"category"`
+	SearchTerm string      `json:"search_term"`
+	Job        *JobPosting `json:"job"`
+}
+
+type checkpointKey struct {
+	category   string
+	searchTerm string
+	jobID      JobID
+}
+
+// checkpoint records every fetched job posting in an append-only JSON lines
+// file, so an interrupted scrape can be resumed without fetching them again.
+type checkpoint struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	enc     *json.Encoder
+	fetched map[checkpointKey]*JobPosting
+
+	truncatedLine bool
+}
+
+// openCheckpoint opens the checkpoint file at path. When resume is true the
+// postings recorded by a previous run are loaded and kept, otherwise the file
+// starts empty.
+func openCheckpoint(path string, resume bool) (*checkpoint, error) {
+	cp := &checkpoint{
+		path:    path,
+		fetched: make(map[checkpointKey]*JobPosting),
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if resume {
+		if err := cp.load(); err != nil {
+			return nil, err
+		}
+		log.Printf("Resuming from checkpoint '%s' with %d jobs already fetched\n", path, len(cp.fetched))
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("could not create directory for checkpoint file '%s': %v", path, err)
+	}
+
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open checkpoint file '%s': %v", path, err)
+	}
+	cp.f = f
+	cp.enc = json.NewEncoder(f)
+
+	if cp.truncatedLine {
+		if _, err := f.WriteString("\n"); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("could not write checkpoint file '%s': %v", path, err)
+		}
+	}
+
+	return cp, nil
+}
+
+func (cp *checkpoint) load() error {
+	data, err := os.ReadFile(cp.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read checkpoint file '%s': %v", cp.path, err)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry checkpointEntry
+		if err := json.Unmarshal(line, &entry); err != nil || entry.Job == nil {
+			// The last line may be cut short if the previous run was killed
+			// while writing it; that job will simply be fetched again.
+			log.Printf("skipping malformed checkpoint line in '%s'", cp.path)
+			continue
+		}
+		cp.fetched[checkpointKey{entry.Category, entry.SearchTerm, entry.Job.JobID}] = entry.Job
+	}
+
+	// Terminate a cut short last line so new entries start on their own line
+	cp.truncatedLine = len(data) > 0 && data[len(data)-1] != '\n'
+
+	return nil
+}
+
+// lookup returns the posting recorded for jid under category and searchTerm.
+func (cp *checkpoint) lookup(category, searchTerm string, jid JobID) (*JobPosting, bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	job, ok := cp.fetched[checkpointKey{category, searchTerm, jid}]
+	return job, ok
+}
+
+// record appends job to the checkpoint file.
+func (cp *checkpoint) record(category, searchTerm string, job *JobPosting) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.fetched[checkpointKey{category, searchTerm, job.JobID}] = job
+	if err := cp.enc.Encode(checkpointEntry{Category: category, SearchTerm: searchTerm, Job: job}); err != nil {
+		return fmt.Errorf("could not write checkpoint for job %s: %v", job.JobID, err)
+	}
+
+	return nil
+}
+
+func (cp *checkpoint) Close() error {
+	return cp.f.Close()
+}
+
+// remove deletes the checkpoint file once the run output has been saved.
+func (cp *checkpoint) remove() error {
+	cp.Close()
+	if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove checkpoint file '%s': %v", cp.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"linkedinScraper/linkedin"
+)
+
+func TestScrapeJobsResumesFromCheckpoint(t *testing.T) {
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang"}}}
+	checkpointFile := filepath.Join(t.TempDir(), "jobs.db.progress")
+	scrape := func(resume bool, failures map[JobID]int) ([]JobCategoryGroup, *postingCounter) {
+		t.Helper()
+
+		client := fakeLinkedIn(t, map[string][]string{"golang": {"1", "2", "3"}})
+		client.MaxAttempts = 1
+		postings := countPostings(client, failures)
+
+		progress, err := openCheckpoint(checkpointFile, resume)
+		if err != nil {
+			t.Fatalf("openCheckpoint: %v", err)
+		}
+		defer progress.Close()
+
+		jobGroups, _, err := scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+			GeoID:        linkedin.GeoIDArgentina,
+			RoleFamilies: linkedin.DefaultRoleFamilies(),
+			Progress:     progress,
+		})
+		if err != nil {
+			t.Fatalf("scrapeJobs: %v", err)
+		}
+		return jobGroups, postings
+	}
+
+	// The first run only gets jobs 1 and 2, and crashes while recording
+	// job 3
+	jobGroups, _ := scrape(false, map[JobID]int{"3": 1})
+	if ids := jobIDs(jobGroups); !slices.Equal(ids, []JobID{"1", "2"}) {
+		t.Fatalf("first run fetched jobs %q, want 1 and 2", ids)
+	}
+	f, err := os.OpenFile(checkpointFile, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"category":"backend","search_term":"golang","job":{"job_id":"3"`)
+	f.Close()
+
+	jobGroups, postings := scrape(true, nil)
+	if ids := jobIDs(jobGroups); !slices.Equal(ids, []JobID{"1", "2", "3"}) {
+		t.Errorf("resumed run got jobs %q, want 1, 2 and 3", ids)
+	}
+	if want := map[JobID]int{"3": 1}; !reflect.DeepEqual(postings.requests, want) {
+		t.Errorf("resumed run requested postings %v, want only job 3", postings.requests)
+	}
+}
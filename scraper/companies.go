@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"linkedinScraper/linkedin"
+)
+
+// companySearchPrefix marks the search terms that list every open role of a
+// company instead of searching for keywords, e.g. "company:1441".
+const companySearchPrefix = "company:"
+
+// loadCompanies reads a JSON file mapping category names to the LinkedIn
+// numeric ids of the companies whose open roles they include, e.g.
+// {"Security": ["1441", "2135371"]}.
+func loadCompanies(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read companies file '%s': %v", path, err)
+	}
+
+	var companies map[string][]string
+	if err := json.Unmarshal(data, &companies); err != nil {
+		return nil, fmt.Errorf("could not decode companies file '%s': %v", path, err)
+	}
+
+	for category, ids := range companies {
+		for _, id := range ids {
+			if id = strings.TrimSpace(id); id == "" || strings.Trim(id, "0123456789") != "" {
+				return nil, fmt.Errorf("invalid company id %q of category '%s' in '%s': must be numeric", id, category, path)
+			}
+		}
+	}
+
+	return companies, nil
+}
+
+// addCompanySearches adds a search listing the open roles of every company in
+// companies to the category it's mapped to, like expandJobCategories does
+// with search terms.
+func addCompanySearches(categories []JobCategory, companies map[string][]string) []JobCategory {
+	searches := make(map[string][]string, len(companies))
+	for category, ids := range companies {
+		for _, id := range ids {
+			searches[category] = append(searches[category], companySearchPrefix+strings.TrimSpace(id))
+		}
+	}
+	return expandJobCategories(categories, searches)
+}
+
+// searchOptions returns the options of the search for searchTerm, which
+// filters by company when it has companySearchPrefix.
+func searchOptions(searchTerm string) linkedin.SearchOptions {
+	if id, ok := strings.CutPrefix(searchTerm, companySearchPrefix); ok {
+		return linkedin.SearchOptions{CompanyIDs: []string{id}}
+	}
+	return linkedin.SearchOptions{Keywords: searchTerm}
+}
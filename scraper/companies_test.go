@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"linkedinScraper/linkedin"
+)
+
+func TestScrapeJobsCompanySearch(t *testing.T) {
+	dir := t.TempDir()
+	companiesFile := filepath.Join(dir, "companies.json")
+	if err := os.WriteFile(companiesFile, []byte(`{"Security": [" 1441 "]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	companies, err := loadCompanies(companiesFile)
+	if err != nil {
+		t.Fatalf("loadCompanies: %v", err)
+	}
+	categories := addCompanySearches([]JobCategory{{Category: "Security", SearchTerms: []string{"pentester"}}}, companies)
+	if want := []string{"pentester", "company:1441"}; !reflect.DeepEqual(categories[0].SearchTerms, want) {
+		t.Fatalf("got search terms %q, want %q", categories[0].SearchTerms, want)
+	}
+
+	progress, err := openCheckpoint(filepath.Join(dir, "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	client := fakeLinkedIn(t, map[string][]string{"pentester": {"1"}, "company:1441": {"2", "3"}})
+	jobGroups, _, err := scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		Progress:     progress,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	for _, searchGroup := range jobGroups[0].Searches {
+		if searchGroup.SearchTerm != "company:1441" {
+			continue
+		}
+		if ids := jobIDs([]JobCategoryGroup{{Searches: []SearchGroup{searchGroup}}}); !reflect.DeepEqual(ids, []JobID{"2", "3"}) {
+			t.Errorf("got jobs %q for the company search, want [2 3]", ids)
+		}
+		if job := searchGroup.Jobs[0]; job.Company != "Company "+job.JobID {
+			t.Errorf("got company %q for job %s, want its posting parsed", job.Company, job.JobID)
+		}
+		return
+	}
+	t.Errorf("got searches %+v, want one for company:1441", jobGroups[0].Searches)
+}
+
+func TestLoadCompaniesRejectsInvalidID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "companies.json")
+	if err := os.WriteFile(path, []byte(`{"Security": ["acme"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCompanies(path); err == nil {
+		t.Error("got nil error for a non numeric company id, want one")
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"linkedinScraper/sqlitedb"
+)
+
+// shingleSize is the number of consecutive words compared between
+// descriptions by descriptionSimilarity.
+const shingleSize = 3
+
+// assignNearDuplicateGroups tags the jobs that are reposts of the same role
+// with a shared DedupGroup: same normalized title and company, and
+// descriptions with a similarity of at least threshold (0 to 1). A group is
+// named after the lowest job ID in it; jobs without near duplicates are left
+// untagged. No job is removed.
+func assignNearDuplicateGroups(jobGroups []JobCategoryGroup, threshold float64) {
+	// A job listed by several searches is only compared once, and tagged
+	// in every search afterwards.
+	seen := make(map[JobID]bool)
+	candidates := make(map[string][]*JobPosting)
+	for _, jobGroup := range jobGroups {
+		for _, searchGroup := range jobGroup.Searches {
+			for _, job := range searchGroup.Jobs {
+				if seen[job.JobID] {
+					continue
+				}
+				seen[job.JobID] = true
+
+				key := normalizeTitle(job.Title) + "\x00" + strings.ToLower(sqlitedb.NormalizeCompanyName(job.Company))
+				candidates[key] = append(candidates[key], job)
+			}
+		}
+	}
+
+	groups := make(map[JobID]string)
+	for _, jobs := range candidates {
+		if len(jobs) < 2 {
+			continue
+		}
+
+		shingles := make([]map[string]bool, len(jobs))
+		for i, job := range jobs {
+			shingles[i] = descriptionShingles(job.Description)
+		}
+
+		// Union-find over the jobs with the same title and company, so
+		// that a chain of similar reposts ends up in a single group.
+		parent := make([]int, len(jobs))
+		for i := range parent {
+			parent[i] = i
+		}
+		var find func(int) int
+		find = func(i int) int {
+			if parent[i] != i {
+				parent[i] = find(parent[i])
+			}
+			return parent[i]
+		}
+
+		for i := range jobs {
+			for j := i + 1; j < len(jobs); j++ {
+				if jaccard(shingles[i], shingles[j]) >= threshold {
+					parent[find(i)] = find(j)
+				}
+			}
+		}
+
+		clusters := make(map[int][]*JobPosting)
+		for i, job := range jobs {
+			root := find(i)
+			clusters[root] = append(clusters[root], job)
+		}
+
+		for _, cluster := range clusters {
+			if len(cluster) < 2 {
+				continue
+			}
+			ids := make([]JobID, len(cluster))
+			for i, job := range cluster {
+				ids[i] = job.JobID
+			}
+			sort.Slice(ids, func(i, j int) bool {
+				if len(ids[i]) != len(ids[j]) {
+					return len(ids[i]) < len(ids[j])
+				}
+				return ids[i] < ids[j]
+			})
+			for _, id := range ids {
+				groups[id] = ids[0]
+			}
+		}
+	}
+
+	for _, jobGroup := range jobGroups {
+		for _, searchGroup := range jobGroup.Searches {
+			for _, job := range searchGroup.Jobs {
+				job.DedupGroup = groups[job.JobID]
+			}
+		}
+	}
+}
+
+// normalizeTitle lowercases title and collapses its whitespace.
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// descriptionShingles returns the sets of shingleSize consecutive words of
+// description, ignoring case and punctuation. Descriptions shorter than that
+// are a single shingle.
+func descriptionShingles(description string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	shingles := make(map[string]bool)
+	if len(words) < shingleSize {
+		shingles[strings.Join(words, " ")] = true
+		return shingles
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		shingles[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+	return shingles
+}
+
+// jaccard returns the Jaccard similarity of two sets.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestAssignNearDuplicateGroups(t *testing.T) {
+	description := "Buscamos un desarrollador Go con experiencia en microservicios, bases de datos SQL y despliegues en la nube."
+	original := &JobPosting{JobID: "20", Title: "Backend Developer", Company: "Acme", Description: description}
+	repost := &JobPosting{JobID: "3", Title: " backend  developer", Company: " ACME ", Description: description + " Trabajo remoto."}
+	distinct := &JobPosting{JobID: "4", Title: "Backend Developer", Company: "Acme", Description: "Sumamos un analista de datos para armar tableros en Power BI y reportes de ventas."}
+	otherCompany := &JobPosting{JobID: "5", Title: "Backend Developer", Company: "Globex", Description: description}
+
+	jobGroups := []JobCategoryGroup{
+		{Category: "backend", Searches: []SearchGroup{
+			{SearchTerm: "golang", Jobs: []*JobPosting{original, repost, distinct}},
+			{SearchTerm: "backend", Jobs: []*JobPosting{otherCompany, repost}},
+		}},
+	}
+	assignNearDuplicateGroups(jobGroups, 0.8)
+
+	tests := []struct {
+		job  *JobPosting
+		want string
+	}{
+		{original, "3"},
+		{repost, "3"},
+		{distinct, ""},
+		{otherCompany, ""},
+	}
+	for _, tt := range tests {
+		if tt.job.DedupGroup != tt.want {
+			t.Errorf("job %s has DedupGroup %q, want %q", tt.job.JobID, tt.job.DedupGroup, tt.want)
+		}
+	}
+}
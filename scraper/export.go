@@ -0,0 +1,122 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// exportListSeparator joins the values of multi-valued columns, such as the
+// categories or skills of a job, in the CSV export.
+const exportListSeparator = ";"
+
+// exportColumns is the header of the CSV export, one column per field of a
+// job, its searches and its analysis.
+var exportColumns = []string{
+	"job_id", "title", "normalized_title", "role_family", "company", "location", "workplace_type",
+	"employment_type", "salary_min", "salary_max", "salary_currency", "salary_period",
+	"posted_at", "fetched_at", "language", "categories", "search_terms",
+	"model", "analyzed_at", "seniority", "skills", "onsite_hybrid_remote", "min_years_experience", "description",
+}
+
+// exportedAnalysis holds the fields of a stored analysis written to the CSV
+// export. Custom fields of other analysis configs are left out.
+type exportedAnalysis struct {
+	Seniority          string   `json:"seniority"`
+	Skills             []string `json:"skills"`
+	OnsiteHybridRemote string   `json:"onsite_hybrid_remote"`
+}
+
+// exportCSV writes every job in db to w as a single CSV with one row per job,
+// joining its categories, search terms and analysis. The analysis columns of
+// jobs not analyzed yet are left blank.
+func exportCSV(db *sql.DB, w io.Writer) error {
+	rows, err := db.Query(`
+		SELECT j.job_id, j.title, j.normalized_title, j.role_family, j.company, j.location, j.workplace_type,
+			j.employment_type, j.salary_min, j.salary_max, j.salary_currency, j.salary_period,
+			j.posted_at, j.fetched_at, j.language,
+			(SELECT group_concat(category_name, ?) FROM (
+				SELECT c.category_name FROM jobs_categories jc
+				JOIN categories c ON c.category_id = jc.category_id
+				WHERE jc.job_id = j.job_id ORDER BY c.category_name)),
+			(SELECT group_concat(search_term, ?) FROM (
+				SELECT s.search_term FROM searches_jobs sj
+				JOIN searches s ON s.search_id = sj.search_id
+				WHERE sj.job_id = j.job_id ORDER BY s.search_term)),
+			a.model, a.analyzed_at, a.analysis, a.min_years_experience, j.description
+		FROM jobs j
+		LEFT JOIN job_analyses a ON a.job_id = j.job_id
+		ORDER BY j.job_id`, exportListSeparator, exportListSeparator)
+	if err != nil {
+		return fmt.Errorf("could not query jobs to export: %v", err)
+	}
+	defer rows.Close()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return fmt.Errorf("could not write CSV export: %v", err)
+	}
+
+	for rows.Next() {
+		var jobID, title, company, description string
+		var normalizedTitle, roleFamily, location, workplaceType, employmentType, salaryCurrency, salaryPeriod sql.NullString
+		var postedAt, fetchedAt, language, categories, searchTerms, model, analyzedAt, analysisJSON sql.NullString
+		var salaryMin, salaryMax sql.NullFloat64
+		var minYears sql.NullInt64
+		err := rows.Scan(&jobID, &title, &normalizedTitle, &roleFamily, &company, &location, &workplaceType,
+			&employmentType, &salaryMin, &salaryMax, &salaryCurrency, &salaryPeriod,
+			&postedAt, &fetchedAt, &language, &categories, &searchTerms,
+			&model, &analyzedAt, &analysisJSON, &minYears, &description)
+		if err != nil {
+			return fmt.Errorf("could not read jobs to export: %v", err)
+		}
+
+		var analysis exportedAnalysis
+		if analysisJSON.Valid {
+			if err := json.Unmarshal([]byte(analysisJSON.String), &analysis); err != nil {
+				return fmt.Errorf("invalid analysis of job '%s': %v", jobID, err)
+			}
+		}
+
+		record := []string{
+			jobID, title, normalizedTitle.String, roleFamily.String, company, location.String, workplaceType.String,
+			employmentType.String, formatNullFloat(salaryMin), formatNullFloat(salaryMax), salaryCurrency.String, salaryPeriod.String,
+			postedAt.String, fetchedAt.String, language.String, categories.String, searchTerms.String,
+			model.String, analyzedAt.String, analysis.Seniority, strings.Join(analysis.Skills, exportListSeparator),
+			analysis.OnsiteHybridRemote, formatNullInt(minYears), description,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("could not write CSV export: %v", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read jobs to export: %v", err)
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("could not write CSV export: %v", err)
+	}
+	return nil
+}
+
+// formatNullInt formats i, or returns an empty string when it is NULL.
+func formatNullInt(i sql.NullInt64) string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.FormatInt(i.Int64, 10)
+}
+
+// formatNullFloat formats f without trailing zeros, or as an empty string
+// when it is NULL.
+func formatNullFloat(f sql.NullFloat64) string {
+	if !f.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(f.Float64, 'f', -1, 64)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"linkedinScraper/sqlitedb"
+)
+
+func TestExportCSV(t *testing.T) {
+	sqliteFile := seedAnalyzedDB(t, map[JobID]string{
+		"1": `{"seniority": "Senior", "skills": ["go", "sql"], "onsite_hybrid_remote": "remote", "benefits": "stock"}`,
+	})
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`UPDATE jobs SET salary_min = 1000, salary_max = 2000.5, salary_currency = 'USD' WHERE job_id = '1'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE job_analyses SET min_years_experience = 3 WHERE job_id = '1'`); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := exportCSV(db, &buf); err != nil {
+		t.Fatalf("exportCSV: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "export.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("got export\n%s\nwant\n%s", got, want)
+	}
+}
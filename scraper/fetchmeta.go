@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"linkedinScraper/sqlitedb"
+)
+
+// fetchMeta describes how fetching the posting of a job went.
+type fetchMeta struct {
+	JobID     JobID
+	FetchedAt time.Time
+	// Attempts is how many requests were sent, retries included.
+	Attempts int
+	// Status is the HTTP status of the last response, 0 when none arrived.
+	Status   int
+	Duration time.Duration
+	// Err is the error the fetch failed with, empty when it succeeded.
+	Err string
+}
+
+// fetchMetaLog collects the fetchMeta of every job fetched in a run. A nil
+// fetchMetaLog discards them.
+type fetchMetaLog struct {
+	mu      sync.Mutex
+	entries []fetchMeta
+}
+
+func (l *fetchMetaLog) record(meta fetchMeta) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, meta)
+}
+
+// save appends the collected entries to the fetch_meta table of the
+// SQLite database at sqliteFile.
+func (l *fetchMetaLog) save(sqliteFile string) error {
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, meta := range l.entries {
+		_, err := tx.Exec(`
+			INSERT INTO fetch_meta (job_id, fetched_at, attempts, final_status, duration_ms, error)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			meta.JobID, meta.FetchedAt.Format(time.RFC3339), meta.Attempts, meta.Status,
+			meta.Duration.Milliseconds(), nullString(meta.Err))
+		if err != nil {
+			return fmt.Errorf("could not insert fetch metadata of job '%s': %v", meta.JobID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"linkedinScraper/linkedin"
+	"linkedinScraper/sqlitedb"
+)
+
+func TestFetchMetaRecordsAttempts(t *testing.T) {
+	dir := t.TempDir()
+	progress, err := openCheckpoint(filepath.Join(dir, "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	client := fakeLinkedIn(t, map[string][]string{"golang": {"1", "2", "3"}})
+	client.RetryDelay = time.Millisecond
+	// Job 1 succeeds on its last attempt, job 2 runs out of them
+	countPostings(client, map[JobID]int{"1": 2, "2": 3})
+
+	fetchMetas := &fetchMetaLog{}
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang"}}}
+	_, _, err = scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		Progress:     progress,
+		FetchMetas:   fetchMetas,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	sqliteFile := filepath.Join(dir, "jobs.db")
+	if err := fetchMetas.save(sqliteFile); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT job_id, attempts, final_status, error FROM fetch_meta`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type meta struct {
+		attempts, status int
+		failed           bool
+	}
+	got := map[JobID]meta{}
+	for rows.Next() {
+		var jid JobID
+		var m meta
+		var errMsg sql.NullString
+		if err := rows.Scan(&jid, &m.attempts, &m.status, &errMsg); err != nil {
+			t.Fatal(err)
+		}
+		m.failed = errMsg.Valid
+		got[jid] = m
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[JobID]meta{
+		"1": {attempts: 3, status: http.StatusOK},
+		"2": {attempts: 3, status: http.StatusServiceUnavailable, failed: true},
+		"3": {attempts: 1, status: http.StatusOK},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got fetch_meta %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package linkedin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newTestClient returns a Client sending its requests to an httptest server
+// with handler, without rate limiting and with short retry delays.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.Client(), rate.NewLimiter(rate.Inf, 1), NewTokenPool([]string{"test-token"}))
+	client.BaseURL = server.URL
+	client.RetryDelay = time.Millisecond
+	client.MaxRetryDelay = time.Millisecond
+	return client
+}
+
+func TestJobListingsUrl(t *testing.T) {
+	client := &Client{BaseURL: "https://example.com"}
+	url := client.jobListingsUrl(SearchOptions{
+		Keywords:       "go developer",
+		GeoID:          GeoIDArgentina,
+		WorkplaceTypes: []WorkplaceType{WorkplaceRemote, WorkplaceHybrid},
+		CompanyIDs:     []string{"1234"},
+	}, 100, 50)
+
+	want := "https://example.com/voyager/api/voyagerJobsDashJobCards?decorationId=com.linkedin.voyager.dash.deco.jobs.search.JobSearchCardsCollection-220&q=jobSearch" +
+		"&query=(origin:JOB_SEARCH_PAGE_LOCATION_AUTOCOMPLETE,keywords:%22go%20developer%22,locationUnion:(geoId:100446943)," +
+		"selectedFilters:(workplaceType:List(2,3),company:List(1234)))&start=100&count=50"
+	if url != want {
+		t.Errorf("jobListingsUrl =\n%s\nwant\n%s", url, want)
+	}
+}
+
+func TestParseWorkplaceType(t *testing.T) {
+	for name, want := range map[string]WorkplaceType{"on-site": WorkplaceOnSite, " Remote ": WorkplaceRemote, "HYBRID": WorkplaceHybrid} {
+		if got, err := ParseWorkplaceType(name); err != nil || got != want {
+			t.Errorf("ParseWorkplaceType(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseWorkplaceType("office"); err == nil {
+		t.Error("ParseWorkplaceType(\"office\") succeeded, want an error")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{"authorized", http.StatusOK, ""},
+		{"unauthorized", http.StatusUnauthorized, "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.WriteHeader(tt.status)
+			}))
+
+			err := client.Check(context.Background())
+			if path != "/voyager/api/me" {
+				t.Errorf("got request to %q, want /voyager/api/me", path)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("got error %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCSRFTokenMatchesCookie(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"ajax:1234567890123456789", "ajax:1234567890123456789"},
+		{`"ajax:1234567890123456789"`, "ajax:1234567890123456789"},
+		{" \"ajax:1234567890123456789\"\n", "ajax:1234567890123456789"},
+	}
+	for _, tt := range tests {
+		var header, cookie string
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get("Csrf-Token")
+			if c, err := r.Cookie("JSESSIONID"); err == nil {
+				cookie = c.Value
+			}
+		}))
+		client.CSRFToken = ParseCSRFToken(tt.value)
+
+		if err := client.Check(context.Background()); err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		if header != tt.want || cookie != tt.want {
+			t.Errorf("ParseCSRFToken(%q) sent Csrf-Token %q and JSESSIONID %q, want both %q", tt.value, header, cookie, tt.want)
+		}
+	}
+}
+
+func TestNewCSRFToken(t *testing.T) {
+	token := NewCSRFToken(nil)
+	if !regexp.MustCompile(`^ajax:\d{19}$`).MatchString(token) {
+		t.Errorf("NewCSRFToken() = %q, want ajax: followed by 19 digits", token)
+	}
+}
+
+func TestClientSpendsRetryBudget(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	const budget = 5
+	client.RetryBudget = rate.NewLimiter(rate.Every(time.Hour), budget)
+
+	const jobs = 20
+	for i := range jobs {
+		if _, err := client.JobPostings(context.Background(), JobID(fmt.Sprint(i))); err == nil {
+			t.Fatalf("JobPostings(%d) succeeded, want the 503 error", i)
+		}
+	}
+
+	if got, want := requests.Load(), int32(jobs+budget); got != want {
+		t.Errorf("sent %d requests, want %d: one per job and %d retries", got, want, budget)
+	}
+}
+
+func TestClientTimesOutSlowRequests(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	client.Timeout = 50 * time.Millisecond
+	client.MaxAttempts = 2
+
+	start := time.Now()
+	err := client.Check(context.Background())
+	if !isTimeout(err) {
+		t.Errorf("got error %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %v, want about two Timeouts of %v", elapsed, client.Timeout)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("sent %d requests, want 2", n)
+	}
+}
+
+func TestVerboseLogRedactsTokens(t *testing.T) {
+	const token = "AQEDAT-secret-li-at"
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// LinkedIn echoing the cookies back is the worst case for the body log
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "no job for session %s", r.Header.Get("Cookie"))
+	}))
+	client.Tokens = NewTokenPool([]string{token})
+	client.Verbose = true
+
+	if _, err := client.JobPostings(context.Background(), "4012345678"); err == nil {
+		t.Fatal("JobPostings succeeded, want the 404 error")
+	}
+	if !strings.Contains(logged.String(), "[REDACTED]") {
+		t.Errorf("verbose log doesn't show the redacted response body:\n%s", logged.String())
+	}
+	if strings.Contains(logged.String(), token) {
+		t.Errorf("verbose log leaks the li_at token:\n%s", logged.String())
+	}
+}
@@ -0,0 +1,65 @@
+package linkedin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cooldown holds back every request of a Client until the time LinkedIn
+// asked to wait with a Retry-After header, so all the goroutines sharing the
+// Client back off together instead of only the one that got the 429.
+type cooldown struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// extend pauses requests until until, unless they are already paused longer.
+// It reports whether the pause was extended.
+func (c *cooldown) extend(until time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !until.After(c.until) {
+		return false
+	}
+	c.until = until
+	return true
+}
+
+// wait blocks until the cooldown is over, including any extension made while
+// waiting, or ctx is done.
+func (c *cooldown) wait(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		remaining := time.Until(c.until)
+		c.mu.Unlock()
+
+		if remaining <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, remaining); err != nil {
+			return err
+		}
+	}
+}
+
+// retryAfter returns the wait resp asks for in its Retry-After header, given
+// either in seconds or as an HTTP date, or 0 when it has none.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
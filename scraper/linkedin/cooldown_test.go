@@ -0,0 +1,86 @@
+package linkedin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{" 3 ", 3 * time.Second},
+		{"-1", 0},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": {tt.value}}}
+		if got := retryAfter(resp, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestClientCoolsDownEveryRequest(t *testing.T) {
+	var mu sync.Mutex
+	var throttledAt time.Time
+	arrivals := map[string]time.Time{}
+	throttled := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jid := strings.TrimPrefix(r.URL.Path, "/voyager/api/jobs/jobPostings/")
+
+		mu.Lock()
+		defer mu.Unlock()
+		if throttledAt.IsZero() {
+			throttledAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			close(throttled)
+			return
+		}
+		arrivals[jid] = time.Now()
+		w.Write([]byte(cannedPosting))
+	}))
+
+	var wg sync.WaitGroup
+	fetch := func(jid JobID) {
+		defer wg.Done()
+		if _, err := client.JobPostings(context.Background(), jid); err != nil {
+			t.Errorf("JobPostings(%s): %v", jid, err)
+		}
+	}
+
+	wg.Add(1)
+	go fetch("1")
+	<-throttled
+	// Give the 429 time to reach the client before the others are sent
+	time.Sleep(50 * time.Millisecond)
+	for _, jid := range []JobID{"2", "3"} {
+		wg.Add(1)
+		go fetch(jid)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, jid := range []JobID{"1", "2", "3"} {
+		arrival, ok := arrivals[jid]
+		if !ok {
+			t.Errorf("job %s was not fetched", jid)
+			continue
+		}
+		if waited := arrival.Sub(throttledAt); waited < time.Second {
+			t.Errorf("job %s was requested %v after the 429, want at least the 1s of Retry-After", jid, waited)
+		}
+	}
+}
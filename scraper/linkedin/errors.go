@@ -0,0 +1,50 @@
+package linkedin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors matched with errors.Is by the errors the Client returns, so callers
+// can branch on the kind of failure.
+var (
+	// ErrRateLimited is LinkedIn answering 429 after every retry.
+	ErrRateLimited = errors.New("rate limited by LinkedIn")
+	// ErrUnauthorized is LinkedIn rejecting the tokens with a 401.
+	ErrUnauthorized = errors.New("unauthorized by LinkedIn")
+	// ErrBlocked999 is LinkedIn flagging the session as a bot, with its
+	// non standard 999 status or a CAPTCHA challenge.
+	ErrBlocked999 = errors.New("blocked by LinkedIn")
+	// ErrNotFound is LinkedIn answering 404, e.g. for a removed posting.
+	ErrNotFound = errors.New("not found on LinkedIn")
+)
+
+// StatusError is returned for a response with an unexpected status code. It
+// matches the sentinel of its status code, if any, with errors.Is.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%d (%s)", e.StatusCode, e.Status)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case statusLinkedInBlocked:
+		return ErrBlocked999
+	case http.StatusNotFound:
+		return ErrNotFound
+	}
+	return nil
+}
+
+func newStatusError(resp *http.Response) *StatusError {
+	return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
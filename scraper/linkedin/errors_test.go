@@ -0,0 +1,43 @@
+package linkedin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClientStatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{statusLinkedInBlocked, ErrBlocked999},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			_, err := client.JobPostings(context.Background(), "4012345678")
+			if err == nil {
+				t.Fatal("got nil error, want one")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+			for _, other := range tests {
+				if other.want != nil && other.want != tt.want && errors.Is(err, other.want) {
+					t.Errorf("error %v also matches %v", err, other.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package linkedin
+
+import "context"
+
+// FetchStats records how the requests made with a context went, for telling
+// apart the jobs LinkedIn served right away from the ones that needed
+// retries. It is not safe for requests made concurrently.
+type FetchStats struct {
+	// Attempts is how many times a request was sent, retries included.
+	Attempts int
+	// Status is the HTTP status of the last response, 0 when none arrived.
+	Status int
+}
+
+type fetchStatsKey struct{}
+
+// WithFetchStats returns a copy of ctx whose requests are recorded in stats.
+func WithFetchStats(ctx context.Context, stats *FetchStats) context.Context {
+	return context.WithValue(ctx, fetchStatsKey{}, stats)
+}
+
+// fetchStatsFrom returns the FetchStats of ctx, or nil when it has none.
+func fetchStatsFrom(ctx context.Context) *FetchStats {
+	stats, _ := ctx.Value(fetchStatsKey{}).(*FetchStats)
+	return stats
+}
@@ -0,0 +1,82 @@
+package linkedin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadExtraFields reads the JSON object at path mapping the name of every
+// extra field to extract to its JSON Pointer (RFC 6901) in the jobPostings
+// response, e.g. {"apply_url": "/applyMethod/companyApplyUrl"}.
+func LoadExtraFields(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read extra fields file '%s': %v", path, err)
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("could not decode extra fields file '%s': %v", path, err)
+	}
+
+	for name, pointer := range fields {
+		if pointer != "" && !strings.HasPrefix(pointer, "/") {
+			return nil, fmt.Errorf("invalid pointer %q of extra field '%s': must start with /", pointer, name)
+		}
+	}
+
+	return fields, nil
+}
+
+// extractFields returns the value at the pointer of every field in the
+// decoded response, skipping the ones not present.
+func extractFields(response any, fields map[string]string) map[string]any {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	extra := make(map[string]any)
+	for name, pointer := range fields {
+		if value, ok := lookupPointer(response, pointer); ok && value != nil {
+			extra[name] = value
+		}
+	}
+
+	if len(extra) == 0 {
+		return nil
+	}
+	return extra
+}
+
+// lookupPointer resolves a JSON Pointer in a value decoded by encoding/json.
+func lookupPointer(value any, pointer string) (any, bool) {
+	if pointer == "" {
+		return value, true
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
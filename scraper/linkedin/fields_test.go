@@ -0,0 +1,65 @@
+package linkedin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseJobPostingExtraFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fields.json")
+	if err := os.WriteFile(path, []byte(`{"employment": "/employmentStatus", "missing": "/applyMethod/companyApplyUrl"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fields, err := LoadExtraFields(path)
+	if err != nil {
+		t.Fatalf("LoadExtraFields: %v", err)
+	}
+
+	job, err := ParseJobPosting("1", []byte(cannedPosting), fields)
+	if err != nil {
+		t.Fatalf("ParseJobPosting: %v", err)
+	}
+
+	want := map[string]any{"employment": "urn:li:fs_employmentStatus:FULL_TIME"}
+	if !reflect.DeepEqual(job.Extra, want) {
+		t.Errorf("got extra fields %v, want %v", job.Extra, want)
+	}
+}
+
+func TestLoadExtraFieldsRejectsInvalidPointer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fields.json")
+	if err := os.WriteFile(path, []byte(`{"title": "title"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadExtraFields(path); err == nil {
+		t.Error("got nil error for a pointer without a leading /, want one")
+	}
+}
+
+func TestLookupPointer(t *testing.T) {
+	value := map[string]any{
+		"a/b":   "slash",
+		"items": []any{"first", map[string]any{"name": "second"}},
+	}
+
+	tests := []struct {
+		pointer string
+		want    any
+		wantOk  bool
+	}{
+		{"/a~1b", "slash", true},
+		{"/items/0", "first", true},
+		{"/items/1/name", "second", true},
+		{"/items/2", nil, false},
+		{"/missing", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := lookupPointer(value, tt.pointer)
+		if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookupPointer(%q) = %v, %v, want %v, %v", tt.pointer, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
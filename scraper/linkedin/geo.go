@@ -0,0 +1,41 @@
+package linkedin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// geoIDs maps location names, lowercased, to their LinkedIn geoId. Names are
+// listed in English and Spanish.
+var geoIDs = map[string]string{
+	"argentina":      GeoIDArgentina,
+	"brazil":         "106057199",
+	"brasil":         "106057199",
+	"chile":          "104621616",
+	"colombia":       "100876405",
+	"mexico":         "103323778",
+	"méxico":         "103323778",
+	"peru":           "102927786",
+	"perú":           "102927786",
+	"spain":          "105646813",
+	"españa":         "105646813",
+	"united states":  "103644278",
+	"estados unidos": "103644278",
+	"uruguay":        "100867946",
+}
+
+// ResolveGeoID returns the geoId of location, either a name known to geoIDs,
+// ignoring case, or a numeric geoId, returned as is.
+func ResolveGeoID(location string) (string, error) {
+	location = strings.TrimSpace(location)
+	if location != "" && strings.Trim(location, "0123456789") == "" {
+		return location, nil
+	}
+
+	name := strings.ToLower(strings.Join(strings.Fields(location), " "))
+	if geoID, ok := geoIDs[name]; ok {
+		return geoID, nil
+	}
+
+	return "", fmt.Errorf("unknown location '%s': use a numeric LinkedIn geoId instead", location)
+}
@@ -0,0 +1,28 @@
+package linkedin
+
+import "testing"
+
+func TestResolveGeoID(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"Argentina", GeoIDArgentina},
+		{"  estados   UNIDOS ", "103644278"},
+		{"México", "103323778"},
+		{"100446943", "100446943"},
+	}
+
+	for _, test := range tests {
+		got, err := ResolveGeoID(test.location)
+		if err != nil || got != test.want {
+			t.Errorf("ResolveGeoID(%q) = %q, %v; want %q", test.location, got, err, test.want)
+		}
+	}
+
+	for _, location := range []string{"", "Atlantis"} {
+		if _, err := ResolveGeoID(location); err == nil {
+			t.Errorf("ResolveGeoID(%q) succeeded, want an error", location)
+		}
+	}
+}
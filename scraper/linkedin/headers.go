@@ -0,0 +1,44 @@
+package linkedin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// DefaultHeaders returns the headers a browser on linkedin.com sends to the
+// Voyager API, so requests don't stand out by lacking them.
+func DefaultHeaders() http.Header {
+	return http.Header{
+		"User-Agent":                {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36"},
+		"Accept":                    {"application/json"},
+		"Accept-Language":           {"es-AR,es;q=0.9,en;q=0.8"},
+		"X-Li-Lang":                 {"es_ES"},
+		"X-Restli-Protocol-Version": {"2.0.0"},
+	}
+}
+
+// LoadHeaders reads the JSON object at path mapping header names to their
+// values and applies it over headers. An empty value removes the header.
+func LoadHeaders(path string, headers http.Header) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read headers file '%s': %v", path, err)
+	}
+
+	var overrides map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("could not decode headers file '%s': %v", path, err)
+	}
+
+	for name, value := range overrides {
+		if value == "" {
+			headers.Del(name)
+			continue
+		}
+		headers.Set(name, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package linkedin
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientSendsHeaders(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+
+	path := filepath.Join(t.TempDir(), "headers.json")
+	if err := os.WriteFile(path, []byte(`{"X-Li-Lang": "en_US", "X-Restli-Protocol-Version": ""}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadHeaders(path, client.Headers); err != nil {
+		t.Fatalf("LoadHeaders: %v", err)
+	}
+
+	if err := client.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	want := DefaultHeaders()
+	want.Set("X-Li-Lang", "en_US")
+	want.Del("X-Restli-Protocol-Version")
+	for name := range want {
+		if got.Get(name) != want.Get(name) {
+			t.Errorf("header %s = %q, want %q", name, got.Get(name), want.Get(name))
+		}
+	}
+	if value := got.Get("X-Restli-Protocol-Version"); value != "" {
+		t.Errorf("header X-Restli-Protocol-Version = %q, want it removed", value)
+	}
+	if got.Get("Csrf-Token") == "" {
+		t.Error("the authentication headers were not sent")
+	}
+}
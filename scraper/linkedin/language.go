@@ -0,0 +1,61 @@
+package linkedin
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Languages returned by DetectLanguage. An empty string means unknown.
+const (
+	LanguageEnglish = "en"
+	LanguageSpanish = "es"
+)
+
+// stopwords are frequent words of each language that are rare in the other
+// one. Words shared by both (e.g. "a", "no") are left out on purpose.
+var stopwords = map[string]map[string]bool{
+	LanguageEnglish: wordSet("the and of to in for with you we our are is will be your on as this that have an or from experience team work"),
+	LanguageSpanish: wordSet("el la los las de del y en para con que por una un es se nuestro nuestra somos buscamos experiencia equipo trabajo conocimientos"),
+}
+
+// minLanguageHits is the number of stopwords needed before guessing a
+// language, so short or mostly technical texts stay unknown.
+const minLanguageHits = 5
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// DetectLanguage guesses whether text is written in English or Spanish by
+// counting the stopwords of each language. It returns "" when there are too
+// few of them or neither language clearly dominates.
+func DetectLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	hits := make(map[string]int)
+	for _, w := range words {
+		for lang, set := range stopwords {
+			if set[w] {
+				hits[lang]++
+			}
+		}
+	}
+
+	en, es := hits[LanguageEnglish], hits[LanguageSpanish]
+	switch {
+	case en+es < minLanguageHits:
+		return ""
+	case en > 2*es:
+		return LanguageEnglish
+	case es > 2*en:
+		return LanguageSpanish
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,20 @@
+package linkedin
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Buscamos un desarrollador backend para nuestro equipo. Es importante tener experiencia con Go y conocimientos de la nube.", LanguageSpanish},
+		{"We are looking for a backend developer to join our team. You will work with Go and have experience in the cloud.", LanguageEnglish},
+		{"Go, Kubernetes, AWS, PostgreSQL", ""},
+		{"Buscamos developers with experience in the team y en el equipo", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
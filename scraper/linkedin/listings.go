@@ -0,0 +1,166 @@
+package linkedin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+)
+
+// jobPostingURN matches the job posting card URNs listed by a search, such
+// as urn:li:fsd_jobPostingCard:(4012345678,JOB_DETAILS), capturing the job
+// ID. The card type suffix and the parentheses are optional so a change in
+// them doesn't break the extraction.
+var jobPostingURN = regexp.MustCompile(`^urn:li:fsd_jobPosting(?:Card)?:\(?([0-9]+)(?:,[A-Z_]+)?\)?$`)
+
+type jobListingsResponse struct {
+	Metadata struct {
+		JobCardPrefetchQueries []struct {
+			PrefetchJobPostingCardUrns []string `json:"prefetchJobPostingCardUrns"`
+		} `json:"jobCardPrefetchQueries"`
+	} `json:"metadata"`
+	Paging struct {
+		Total int `json:"total"`
+		Start int `json:"start"`
+		Count int `json:"count"`
+	} `json:"paging"`
+}
+
+// JobListings streams the IDs of the jobs found by a search. Once the returned
+// channel is closed, the error channel yields the error that stopped the
+// pagination early, or nil. Canceling ctx stops the producer goroutine.
+func (c *Client) JobListings(ctx context.Context, opts SearchOptions) (<-chan JobID, <-chan error) {
+	result := make(chan JobID)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(result)
+
+		start := 0
+		count := 100
+		done := false
+
+		// Pages can overlap when LinkedIn serves a different window than
+		// the one requested, so IDs already sent are skipped.
+		sent := make(map[JobID]bool)
+
+		// maxPages bounds the loop once the first page reports the total,
+		// in case LinkedIn keeps reporting more results than it serves.
+		pages := 0
+		maxPages := 1
+
+		for !done && pages < maxPages {
+			pages++
+			content, err := c.jobListingsPage(ctx, opts, start, count)
+			if err != nil {
+				errc <- err
+				return
+			}
+
+			// LinkedIn returns no prefetch queries past the last result and
+			// while soft-blocking, either way there is nothing more to read.
+			if len(content.Metadata.JobCardPrefetchQueries) == 0 {
+				c.verbosef("jobListings: no prefetch queries for search %q at start %d, stopping", opts.Keywords, start)
+				return
+			}
+
+			urns := content.Metadata.JobCardPrefetchQueries[0].PrefetchJobPostingCardUrns
+
+			// A throttled page can come back empty while Paging.Total still
+			// claims more results; start would never advance, so stop here.
+			if len(urns) == 0 {
+				log.Printf("jobListings: empty page for search %q at start %d of %d, stopping", opts.Keywords, start, content.Paging.Total)
+				return
+			}
+
+			for _, id := range urns {
+				jid, ok := parseJobPostingURN(id)
+				if !ok {
+					log.Printf("jobListings: skipping unparseable job URN %q", id)
+					continue
+				}
+				if sent[jid] {
+					continue
+				}
+				sent[jid] = true
+
+				select {
+				case result <- jid:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			next, err := nextStart(start, len(urns), content.Paging.Start, content.Paging.Count)
+			if err != nil {
+				log.Printf("jobListings: %v for search %q, stopping", err, opts.Keywords)
+				return
+			}
+
+			pageSize := next - start
+			start = next
+			done = start >= content.Paging.Total
+			maxPages = content.Paging.Total/pageSize + 1
+		}
+	}()
+
+	return result, errc
+}
+
+// jobListingsPage fetches the page of a search starting at start, closing the
+// response body before returning.
+func (c *Client) jobListingsPage(ctx context.Context, opts SearchOptions, start, count int) (jobListingsResponse, error) {
+	content := jobListingsResponse{}
+
+	resp, err := c.doRequest(ctx, c.jobListingsUrl(opts, start, count))
+	if err != nil {
+		return content, fmt.Errorf("error making jobListings request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return content, fmt.Errorf("error jobListings response was not OK: %w", newStatusError(resp))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		return content, fmt.Errorf("error decoding jobListings response: %w", err)
+	}
+
+	return content, nil
+}
+
+// nextStart returns the start of the page following the one requested at
+// start, which held n results. LinkedIn reports the window it actually served
+// in Paging.Start and Paging.Count, which may be smaller than requested; the
+// next page starts right after it so no result is skipped or repeated. An
+// error is returned when the window doesn't move pagination forward.
+func nextStart(start, n, pagingStart, pagingCount int) (int, error) {
+	if pagingCount <= 0 {
+		// No window reported, advance by the results received
+		return start + n, nil
+	}
+
+	if pagingStart != start {
+		log.Printf("jobListings: requested start %d but LinkedIn served start %d", start, pagingStart)
+	}
+
+	next := pagingStart + min(pagingCount, n)
+	if next <= start {
+		return 0, fmt.Errorf("page window %d+%d does not advance past start %d", pagingStart, pagingCount, start)
+	}
+
+	return next, nil
+}
+
+// parseJobPostingURN returns the numeric job ID in urn, or false when urn
+// is not a job posting URN.
+func parseJobPostingURN(urn string) (JobID, bool) {
+	m := jobPostingURN.FindStringSubmatch(urn)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
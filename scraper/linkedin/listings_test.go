@@ -0,0 +1,145 @@
+package linkedin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// listingsPage returns a canned voyagerJobsDashJobCards response listing ids,
+// served as the window start+len(ids) of total results.
+func listingsPage(start, total int, ids ...string) string {
+	urns := make([]string, len(ids))
+	for i, id := range ids {
+		urns[i] = fmt.Sprintf(`"urn:li:fsd_jobPostingCard:(%s,JOB_DETAILS)"`, id)
+	}
+
+	return fmt.Sprintf(`{
+		"metadata": {"jobCardPrefetchQueries": [{"prefetchJobPostingCardUrns": [%s]}]},
+		"paging": {"total": %d, "start": %d, "count": %d}
+	}`, strings.Join(urns, ","), total, start, len(ids))
+}
+
+// collectListings drains the channels returned by JobListings.
+func collectListings(t *testing.T, client *Client, opts SearchOptions) ([]JobID, error) {
+	t.Helper()
+
+	listings, errc := client.JobListings(context.Background(), opts)
+	var ids []JobID
+	for jid := range listings {
+		ids = append(ids, jid)
+	}
+	return ids, <-errc
+}
+
+func TestJobListingsPaginates(t *testing.T) {
+	// Serves 2 results per page even though 100 are requested, the second
+	// page overlapping the first one.
+	pages := map[int]string{
+		0: listingsPage(0, 5, "1", "2"),
+		2: listingsPage(2, 5, "2", "3"),
+		4: listingsPage(4, 5, "4", "5"),
+	}
+
+	var mu sync.Mutex
+	var requested []int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/voyager/api/voyagerJobsDashJobCards" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if !strings.Contains(r.URL.RawQuery, "geoId:100446943") {
+			t.Errorf("query %q does not search in the requested geoId", r.URL.RawQuery)
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		mu.Lock()
+		requested = append(requested, start)
+		mu.Unlock()
+		page, ok := pages[start]
+		if !ok {
+			t.Errorf("unexpected page requested at start %d", start)
+			page = listingsPage(start, 5)
+		}
+		fmt.Fprint(w, page)
+	}))
+
+	ids, err := collectListings(t, client, SearchOptions{Keywords: "golang", GeoID: GeoIDArgentina})
+	if err != nil {
+		t.Fatalf("JobListings: %v", err)
+	}
+
+	if want := []JobID{"1", "2", "3", "4", "5"}; !slices.Equal(ids, want) {
+		t.Errorf("got job IDs %v, want %v", ids, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []int{0, 2, 4}; !slices.Equal(requested, want) {
+		t.Errorf("requested pages at %v, want %v", requested, want)
+	}
+}
+
+func TestJobListingsStopsWithoutPrefetchQueries(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"metadata": {}, "paging": {"total": 1000, "start": 0, "count": 0}}`)
+	}))
+
+	ids, err := collectListings(t, client, SearchOptions{Keywords: "golang", GeoID: GeoIDArgentina})
+	if err != nil {
+		t.Errorf("JobListings: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("got job IDs %v, want none", ids)
+	}
+}
+
+func TestJobListingsReportsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"not found", http.StatusNotFound, `{}`, "not OK"},
+		{"malformed JSON", http.StatusOK, `{"metadata": `, "error decoding jobListings response"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+
+			_, err := collectListings(t, client, SearchOptions{Keywords: "golang", GeoID: GeoIDArgentina})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseJobPostingURN(t *testing.T) {
+	tests := []struct {
+		urn  string
+		want JobID
+		ok   bool
+	}{
+		{"urn:li:fsd_jobPostingCard:(4012345678,JOB_DETAILS)", "4012345678", true},
+		{"urn:li:fsd_jobPostingCard:(4012345678,JOBS_SEARCH)", "4012345678", true},
+		{"urn:li:fsd_jobPosting:4012345678", "4012345678", true},
+		{"urn:li:fsd_company:1234", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseJobPostingURN(test.urn)
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseJobPostingURN(%q) = %q, %v; want %q, %v", test.urn, got, ok, test.want, test.ok)
+		}
+	}
+}
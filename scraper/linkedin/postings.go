@@ -0,0 +1,283 @@
+package linkedin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobPosting is the data extracted from a LinkedIn job posting.
+type JobPosting struct {
+	JobID          string     `json:"job_id"`
+	Company        string     `json:"company"`
+	Description    string     `json:"description"`
+	Title          string     `json:"title"`
+	EmploymentType string     `json:"employment_type,omitempty"`
+	JobFunctions   []string   `json:"job_functions,omitempty"`
+	Salary         *Salary    `json:"salary,omitempty"`
+	PostedAt       *time.Time `json:"posted_at,omitempty"`
+	// Location is where the job is, as LinkedIn formats it, e.g. "Buenos
+	// Aires, Argentina".
+	Location string `json:"location,omitempty"`
+	// WorkplaceType is where the job is done as LinkedIn lists it: on_site,
+	// hybrid or remote. Empty when the posting doesn't say.
+	WorkplaceType string `json:"workplace_type,omitempty"`
+	// Language is the language the description is written in, as returned
+	// by DetectLanguage.
+	Language string `json:"language,omitempty"`
+	// LinkedInSkills are the skills LinkedIn itself tagged the posting with,
+	// as returned by Client.JobSkills.
+	LinkedInSkills []string `json:"linkedin_skills,omitempty"`
+	// Extra holds the fields configured in Client.ExtraFields, by name.
+	Extra map[string]any `json:"extra,omitempty"`
+	// DedupGroup is shared by the near duplicates of the posting, reposts
+	// of the same role under another job ID. Empty when it has none.
+	DedupGroup string `json:"dedup_group,omitempty"`
+	// FetchedAt is when the posting was requested from LinkedIn.
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+	// NormalizedTitle is the role of Title, as returned by NormalizeTitle.
+	NormalizedTitle string `json:"normalized_title,omitempty"`
+	// RoleFamily is the family of Title, as returned by ClassifyRole. Empty
+	// when no family matches.
+	RoleFamily string `json:"role_family,omitempty"`
+}
+
+// Salary is the compensation range LinkedIn publishes for some postings. Any
+// of its fields may be missing.
+type Salary struct {
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+	Period   string   `json:"period,omitempty"`
+}
+
+type jobPostingsResponse struct {
+	CompanyDetails struct {
+		Company struct {
+			Result struct {
+				Name string `json:"name"`
+			} `json:"companyResolutionResult"`
+		} `json:"com.linkedin.voyager.deco.jobs.web.shared.WebJobPostingCompany"`
+	}
+	Description struct {
+		Text string `json:"text"`
+	} `json:"description"`
+	Title                     string   `json:"title"`
+	EmploymentStatus          string   `json:"employmentStatus"`
+	FormattedEmploymentStatus string   `json:"formattedEmploymentStatus"`
+	FormattedJobFunctions     []string `json:"formattedJobFunctions"`
+	FormattedLocation         string   `json:"formattedLocation"`
+	WorkplaceTypes            []string `json:"workplaceTypes"` // URNs, e.g. urn:li:fs_workplaceType:2
+	WorkRemoteAllowed         *bool    `json:"workRemoteAllowed"`
+	ListedAt                  int64    `json:"listedAt"` // milliseconds since the epoch
+	SalaryInsights            *struct {
+		CompensationBreakdown []struct {
+			MinSalary    json.Number `json:"minSalary"`
+			MaxSalary    json.Number `json:"maxSalary"`
+			CurrencyCode string      `json:"currencyCode"`
+			PayPeriod    string      `json:"payPeriod"`
+		} `json:"compensationBreakdown"`
+	} `json:"salaryInsights"`
+}
+
+// JobPostings fetches the posting of the job jid. When c.RawDir is set, the
+// raw response is also written to <RawDir>/<jid>.json. The job is abandoned
+// once c.JobDeadline passes, even if attempts are left.
+func (c *Client) JobPostings(ctx context.Context, jid JobID) (*JobPosting, error) {
+	jobCtx := ctx
+	if c.JobDeadline > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, c.JobDeadline)
+		defer cancel()
+	}
+
+	resp, err := c.doRequest(jobCtx, c.jobPostingsUrl(jid))
+	if err != nil {
+		if ctx.Err() == nil && jobCtx.Err() != nil {
+			return nil, fmt.Errorf("abandoning job %s after %v: %w", jid, c.JobDeadline, err)
+		}
+		return nil, fmt.Errorf("error making jobPostings request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error jobPostings response was not OK: %w", newStatusError(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading jobPostings response: %w", err)
+	}
+
+	if c.RawDir != "" {
+		if err := writeRawPosting(c.RawDir, jid, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return ParseJobPosting(jid, data, c.ExtraFields)
+}
+
+// ParseJobPosting extracts the posting of the job jid from a raw jobPostings
+// response, such as the ones stored in a Client's RawDir. extraFields maps
+// the name of additional fields to extract to their JSON Pointer in data.
+func ParseJobPosting(jid JobID, data []byte, extraFields map[string]string) (*JobPosting, error) {
+	content := jobPostingsResponse{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("error decoding jobPostings response: %v", err)
+	}
+
+	var extra map[string]any
+	if len(extraFields) > 0 {
+		var raw any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("error decoding jobPostings response: %v", err)
+		}
+		extra = extractFields(raw, extraFields)
+	}
+
+	description := cleanDescription(content.Description.Text)
+
+	return &JobPosting{
+		JobID:          jid,
+		Company:        content.CompanyDetails.Company.Result.Name,
+		Description:    description,
+		Title:          content.Title,
+		EmploymentType: employmentType(content),
+		JobFunctions:   content.FormattedJobFunctions,
+		Salary:         salary(content),
+		PostedAt:       postedAt(content),
+		Location:       strings.TrimSpace(content.FormattedLocation),
+		WorkplaceType:  workplaceType(content),
+		Language:       DetectLanguage(description),
+		Extra:          extra,
+	}, nil
+}
+
+// validJobID matches the job IDs that are safe to use as a file name.
+var validJobID = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// writeRawPosting writes the raw response of the job jid to dir/<jid>.json.
+// Job IDs come from LinkedIn, so anything that could escape dir is refused.
+func writeRawPosting(dir string, jid JobID, data []byte) error {
+	if !validJobID.MatchString(jid) {
+		return fmt.Errorf("refusing to store raw response of job with unsafe ID %q", jid)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("could not create raw response directory '%s': %v", dir, err)
+	}
+
+	path := filepath.Join(dir, jid+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("could not write raw response of job %s: %v", jid, err)
+	}
+
+	return nil
+}
+
+// workplaceType returns the name of the first known workplace type of the
+// posting. Postings without one that allow remote work are remote, and the
+// rest are left empty since LinkedIn doesn't tell on-site from hybrid then.
+func workplaceType(content jobPostingsResponse) string {
+	for _, urn := range content.WorkplaceTypes {
+		id, err := strconv.Atoi(urn[strings.LastIndex(urn, ":")+1:])
+		if err != nil {
+			continue
+		}
+		if name, ok := workplaceTypeValues[WorkplaceType(id)]; ok {
+			return name
+		}
+	}
+
+	if content.WorkRemoteAllowed != nil && *content.WorkRemoteAllowed {
+		return workplaceTypeValues[WorkplaceRemote]
+	}
+	return ""
+}
+
+// postedAt returns when the job was listed, or nil if LinkedIn did not say.
+func postedAt(content jobPostingsResponse) *time.Time {
+	if content.ListedAt <= 0 {
+		return nil
+	}
+
+	t := time.UnixMilli(content.ListedAt).UTC()
+	return &t
+}
+
+// salary returns the first compensation range in the posting's salary
+// insights, or nil when LinkedIn does not publish one.
+func salary(content jobPostingsResponse) *Salary {
+	if content.SalaryInsights == nil || len(content.SalaryInsights.CompensationBreakdown) == 0 {
+		return nil
+	}
+
+	breakdown := content.SalaryInsights.CompensationBreakdown[0]
+	s := &Salary{
+		Min:      parseAmount(breakdown.MinSalary),
+		Max:      parseAmount(breakdown.MaxSalary),
+		Currency: breakdown.CurrencyCode,
+		Period:   breakdown.PayPeriod,
+	}
+
+	if s.Min == nil && s.Max == nil {
+		return nil
+	}
+
+	return s
+}
+
+func parseAmount(n json.Number) *float64 {
+	f, err := n.Float64()
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+var (
+	// htmlBreakTag matches the tags that separate lines or paragraphs.
+	htmlBreakTag = regexp.MustCompile(`(?i)<(br|/?p|/div|/li|/h[1-6]|/ul|/ol)\s*/?>`)
+	// htmlTag only matches well known HTML tags with well formed attributes,
+	// so text like "a < b && b > c" or "List<String>" in code snippets is
+	// left alone.
+	htmlTag = regexp.MustCompile(`(?i)</?(a|b|br|div|em|h[1-6]|i|li|ol|p|span|strong|u|ul)(\s+[a-z-]+(=("[^"]*"|'[^']*'|[^\s"'<>]+))?)*\s*/?>`)
+	// blankLines matches runs of empty lines left behind by removed tags.
+	blankLines = regexp.MustCompile(`\n\s*\n(\s*\n)+`)
+)
+
+// cleanDescription removes residual HTML markup from a job description and
+// unescapes its HTML entities. Tags are stripped before unescaping so that
+// escaped text like "&lt;div&gt;" survives as "<div>".
+func cleanDescription(description string) string {
+	description = htmlBreakTag.ReplaceAllString(description, "\n")
+	description = htmlTag.ReplaceAllString(description, "")
+	description = html.UnescapeString(description)
+	description = blankLines.ReplaceAllString(description, "\n\n")
+	return strings.TrimSpace(description)
+}
+
+// employmentType returns the human readable employment status ("Full-time",
+// "Contract", ...), falling back to the suffix of the employmentStatus URN
+// (urn:li:fs_employmentStatus:FULL_TIME) when the formatted one is missing.
+func employmentType(content jobPostingsResponse) string {
+	if content.FormattedEmploymentStatus != "" {
+		return content.FormattedEmploymentStatus
+	}
+
+	if i := strings.LastIndex(content.EmploymentStatus, ":"); i >= 0 {
+		return content.EmploymentStatus[i+1:]
+	}
+
+	return content.EmploymentStatus
+}
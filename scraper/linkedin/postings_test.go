@@ -0,0 +1,232 @@
+package linkedin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cannedPosting is a trimmed jobPostings response with the fields the scraper
+// reads.
+const cannedPosting = `{
+	"companyDetails": {
+		"com.linkedin.voyager.deco.jobs.web.shared.WebJobPostingCompany": {
+			"companyResolutionResult": {"name": "Acme"}
+		}
+	},
+	"description": {"text": "<p>Buscamos un desarrollador Go para nuestro equipo de trabajo.</p><p>Experiencia con la nube &amp; los contenedores es un plus para el equipo.</p>"},
+	"title": "Backend Developer",
+	"employmentStatus": "urn:li:fs_employmentStatus:FULL_TIME",
+	"formattedJobFunctions": ["Engineering"],
+	"formattedLocation": " Buenos Aires, Argentina ",
+	"workplaceTypes": ["urn:li:fs_workplaceType:3"],
+	"listedAt": 1735787045000,
+	"salaryInsights": {
+		"compensationBreakdown": [
+			{"minSalary": 1000, "maxSalary": 2000.5, "currencyCode": "USD", "payPeriod": "MONTHLY"}
+		]
+	}
+}`
+
+func TestJobPostings(t *testing.T) {
+	var client *Client
+	client = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/voyager/api/jobs/jobPostings/4012345678" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		if cookie, err := r.Cookie("li_at"); err != nil || cookie.Value != "test-token" {
+			t.Errorf("li_at cookie = %v, %v; want the token of the pool", cookie, err)
+		}
+		if r.Header.Get("Csrf-Token") != client.CSRFToken {
+			t.Errorf("Csrf-Token = %q, want %q", r.Header.Get("Csrf-Token"), client.CSRFToken)
+		}
+		fmt.Fprint(w, cannedPosting)
+	}))
+
+	job, err := client.JobPostings(context.Background(), "4012345678")
+	if err != nil {
+		t.Fatalf("JobPostings: %v", err)
+	}
+
+	if job.JobID != "4012345678" || job.Company != "Acme" || job.Title != "Backend Developer" {
+		t.Errorf("got job %q at %q titled %q", job.JobID, job.Company, job.Title)
+	}
+	wantDescription := "Buscamos un desarrollador Go para nuestro equipo de trabajo.\n\nExperiencia con la nube & los contenedores es un plus para el equipo."
+	if job.Description != wantDescription {
+		t.Errorf("Description = %q, want %q", job.Description, wantDescription)
+	}
+	if job.EmploymentType != "FULL_TIME" {
+		t.Errorf("EmploymentType = %q, want FULL_TIME", job.EmploymentType)
+	}
+	if job.Location != "Buenos Aires, Argentina" {
+		t.Errorf("Location = %q, want %q", job.Location, "Buenos Aires, Argentina")
+	}
+	if job.WorkplaceType != "hybrid" {
+		t.Errorf("WorkplaceType = %q, want hybrid", job.WorkplaceType)
+	}
+	if job.Language != LanguageSpanish {
+		t.Errorf("Language = %q, want %q", job.Language, LanguageSpanish)
+	}
+	if want := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC); job.PostedAt == nil || !job.PostedAt.Equal(want) {
+		t.Errorf("PostedAt = %v, want %v", job.PostedAt, want)
+	}
+	if s := job.Salary; s == nil || s.Min == nil || *s.Min != 1000 || s.Max == nil || *s.Max != 2000.5 || s.Currency != "USD" || s.Period != "MONTHLY" {
+		t.Errorf("Salary = %+v, want 1000-2000.5 USD MONTHLY", s)
+	}
+}
+
+func TestJobPostingsRetriesTransientStatus(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, cannedPosting)
+	}))
+
+	stats := &FetchStats{}
+	job, err := client.JobPostings(WithFetchStats(context.Background(), stats), "4012345678")
+	if err != nil {
+		t.Fatalf("JobPostings: %v", err)
+	}
+	if job.Company != "Acme" {
+		t.Errorf("Company = %q, want Acme", job.Company)
+	}
+	if stats.Attempts != 2 || stats.Status != http.StatusOK {
+		t.Errorf("got %d attempts ending in %d, want 2 ending in 200", stats.Attempts, stats.Status)
+	}
+}
+
+func TestJobPostingsNotFound(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler())
+
+	_, err := client.JobPostings(context.Background(), "4012345678")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want ErrNotFound", err)
+	}
+}
+
+func TestJobPostingsGivesUpAtJobDeadline(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	client.MaxAttempts = 1000
+	client.RetryDelay = 10 * time.Millisecond
+	client.MaxRetryDelay = 10 * time.Millisecond
+	client.JobDeadline = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := client.JobPostings(context.Background(), "4012345678")
+	if err == nil || !strings.Contains(err.Error(), "abandoning job 4012345678") {
+		t.Errorf("got error %v, want the job abandoned", err)
+	}
+	if elapsed := time.Since(start); elapsed > client.JobDeadline+time.Second {
+		t.Errorf("gave up after %v, want about the %v of JobDeadline", elapsed, client.JobDeadline)
+	}
+}
+
+func TestJobPostingsWritesRawResponse(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, cannedPosting)
+	}))
+	client.RawDir = filepath.Join(t.TempDir(), "raw")
+
+	if _, err := client.JobPostings(context.Background(), "4012345678"); err != nil {
+		t.Fatalf("JobPostings: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(client.RawDir, "4012345678.json"))
+	if err != nil {
+		t.Fatalf("could not read raw response: %v", err)
+	}
+	if string(data) != cannedPosting {
+		t.Errorf("raw response = %q, want the body served", data)
+	}
+
+	for _, jid := range []JobID{"../escaped", "a/b", ""} {
+		if _, err := client.JobPostings(context.Background(), jid); err == nil {
+			t.Errorf("JobPostings(%q) succeeded, want the unsafe ID refused", jid)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(client.RawDir, "..", "escaped.json")); !os.IsNotExist(err) {
+		t.Errorf("a raw response was written outside RawDir: %v", err)
+	}
+}
+
+func TestParseJobPostingWorkplaceType(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"on-site", `{"workplaceTypes": ["urn:li:fs_workplaceType:1"]}`, "on_site"},
+		{"remote over workRemoteAllowed", `{"workplaceTypes": ["urn:li:fs_workplaceType:2"], "workRemoteAllowed": false}`, "remote"},
+		{"unknown URN skipped", `{"workplaceTypes": ["urn:li:fs_workplaceType:9", "urn:li:fs_workplaceType:3"]}`, "hybrid"},
+		{"workRemoteAllowed fallback", `{"workRemoteAllowed": true}`, "remote"},
+		{"workRemoteAllowed false", `{"workRemoteAllowed": false}`, ""},
+		{"neither field", `{"title": "Backend Developer"}`, ""},
+	}
+	for _, tt := range tests {
+		job, err := ParseJobPosting("1", []byte(tt.payload), nil)
+		if err != nil {
+			t.Fatalf("%s: ParseJobPosting: %v", tt.name, err)
+		}
+		if job.WorkplaceType != tt.want {
+			t.Errorf("%s: WorkplaceType = %q, want %q", tt.name, job.WorkplaceType, tt.want)
+		}
+	}
+}
+
+func TestParseJobPostingSalary(t *testing.T) {
+	minSalary := 1500.0
+	tests := []struct {
+		name    string
+		payload string
+		want    *Salary
+	}{
+		{"minimum only", `{"salaryInsights": {"compensationBreakdown": [{"minSalary": 1500, "currencyCode": "ARS", "payPeriod": "YEARLY"}]}}`,
+			&Salary{Min: &minSalary, Currency: "ARS", Period: "YEARLY"}},
+		{"no salary insights", `{"title": "Backend Developer"}`, nil},
+		{"empty breakdown", `{"salaryInsights": {"compensationBreakdown": []}}`, nil},
+		{"no amounts", `{"salaryInsights": {"compensationBreakdown": [{"currencyCode": "USD"}]}}`, nil},
+	}
+	for _, tt := range tests {
+		job, err := ParseJobPosting("1", []byte(tt.payload), nil)
+		if err != nil {
+			t.Fatalf("%s: ParseJobPosting: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(job.Salary, tt.want) {
+			t.Errorf("%s: Salary = %+v, want %+v", tt.name, job.Salary, tt.want)
+		}
+	}
+}
+
+func TestCleanDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"Go &amp; Kubernetes", "Go & Kubernetes"},
+		{"<p>Buscamos <strong>devs</strong></p><p>Remoto</p>", "Buscamos devs\n\nRemoto"},
+		{"Line one<br>Line two<BR/>Line three", "Line one\nLine two\nLine three"},
+		{"<ul><li>Go</li><li>SQL</li></ul>", "Go\nSQL"},
+		{"Escaped markup &lt;div&gt; is kept", "Escaped markup <div> is kept"},
+		{"if a < b && b > c { return }", "if a < b && b > c { return }"},
+		{`<a href="https://example.com" target=_blank>Apply</a> <span class='x'>now</span>`, "Apply now"},
+		{"Generics like List<String> or <b && c>", "Generics like List<String> or <b && c>"},
+		{"  <p></p><p></p><p>Trimmed</p>  ", "Trimmed"},
+	}
+	for _, tt := range tests {
+		if got := cleanDescription(tt.description); got != tt.want {
+			t.Errorf("cleanDescription(%q) = %q, want %q", tt.description, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package linkedin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type jobSkillsResponse struct {
+	SkillMatchStatuses []struct {
+		Skill struct {
+			Name string `json:"name"`
+		} `json:"skill"`
+	} `json:"skillMatchStatuses"`
+}
+
+func (c *Client) jobSkillsUrl(jid JobID) string {
+	return c.BaseURL + "/voyager/api/voyagerAssessmentsDashJobSkillMatchInsight/urn%3Ali%3Afsd_jobSkillMatchInsight%3A" + jid + "?decorationId=com.linkedin.voyager.dash.deco.assessments.FullJobSkillMatchInsight-17"
+}
+
+// JobSkills fetches the skills LinkedIn tagged the job jid with. Postings
+// without a skills section return no skills and no error.
+func (c *Client) JobSkills(ctx context.Context, jid JobID) ([]string, error) {
+	resp, err := c.doRequest(ctx, c.jobSkillsUrl(jid))
+	if err != nil {
+		return nil, fmt.Errorf("error making jobSkills request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error jobSkills response was not OK: %w", newStatusError(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading jobSkills response: %w", err)
+	}
+
+	return parseJobSkills(data)
+}
+
+// parseJobSkills extracts the skill names of a jobSkills response.
+func parseJobSkills(data []byte) ([]string, error) {
+	content := jobSkillsResponse{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("error decoding jobSkills response: %v", err)
+	}
+
+	var skills []string
+	for _, status := range content.SkillMatchStatuses {
+		if name := strings.TrimSpace(status.Skill.Name); name != "" {
+			skills = append(skills, name)
+		}
+	}
+	return skills, nil
+}
@@ -0,0 +1,55 @@
+package linkedin
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJobSkills(t *testing.T) {
+	data := []byte(`{
+		"skillMatchStatuses": [
+			{"skill": {"name": "Go"}, "skillOnProfile": false},
+			{"skill": {"name": " Kubernetes "}},
+			{"skill": {"name": ""}},
+			{"skill": {}}
+		]
+	}`)
+
+	skills, err := parseJobSkills(data)
+	if err != nil {
+		t.Fatalf("parseJobSkills returned an error: %v", err)
+	}
+	if want := []string{"Go", "Kubernetes"}; !reflect.DeepEqual(skills, want) {
+		t.Errorf("got %v, want %v", skills, want)
+	}
+
+	if _, err := parseJobSkills([]byte("not json")); err == nil {
+		t.Error("got nil error for an invalid response, want one")
+	}
+}
+
+func TestJobSkills(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "fsd_jobSkillMatchInsight:123") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"skillMatchStatuses": [{"skill": {"name": "SQL"}}]}`))
+	}))
+
+	skills, err := client.JobSkills(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("JobSkills returned an error: %v", err)
+	}
+	if want := []string{"SQL"}; !reflect.DeepEqual(skills, want) {
+		t.Errorf("got %v, want %v", skills, want)
+	}
+
+	skills, err = client.JobSkills(context.Background(), "456")
+	if err != nil || skills != nil {
+		t.Errorf("got %v, %v for a posting without skills, want nil, nil", skills, err)
+	}
+}
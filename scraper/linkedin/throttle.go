@@ -0,0 +1,65 @@
+package linkedin
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// AdaptiveThrottle tunes the rate of a rate.Limiter AIMD style: the rate
+// grows by a fixed step after a run of successful responses and is halved
+// whenever LinkedIn throttles a request, staying within [Min, Max].
+type AdaptiveThrottle struct {
+	mu      sync.Mutex
+	limiter *rate.Limiter
+
+	Min, Max rate.Limit
+	// Step is added to the rate after SuccessesPerStep successful responses
+	// in a row.
+	Step             rate.Limit
+	SuccessesPerStep int
+
+	successes int
+}
+
+// NewAdaptiveThrottle returns an AdaptiveThrottle tuning limiter, whose
+// current rate is the starting point, between min and max.
+func NewAdaptiveThrottle(limiter *rate.Limiter, min, max rate.Limit) *AdaptiveThrottle {
+	return &AdaptiveThrottle{
+		limiter:          limiter,
+		Min:              min,
+		Max:              max,
+		Step:             0.5,
+		SuccessesPerStep: 20,
+	}
+}
+
+// Limit returns the current rate.
+func (t *AdaptiveThrottle) Limit() rate.Limit {
+	return t.limiter.Limit()
+}
+
+// OnSuccess records a successful response, increasing the rate after
+// SuccessesPerStep of them in a row.
+func (t *AdaptiveThrottle) OnSuccess() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.successes++
+	if t.successes < t.SuccessesPerStep {
+		return
+	}
+	t.successes = 0
+
+	t.limiter.SetLimit(min(t.limiter.Limit()+t.Step, t.Max))
+}
+
+// OnThrottled records a request LinkedIn throttled (429 or 999), halving
+// the rate.
+func (t *AdaptiveThrottle) OnThrottled() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.successes = 0
+	t.limiter.SetLimit(max(t.limiter.Limit()/2, t.Min))
+}
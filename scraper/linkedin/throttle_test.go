@@ -0,0 +1,81 @@
+package linkedin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestAdaptiveThrottle(t *testing.T) {
+	limiter := rate.NewLimiter(10, 1)
+	throttle := NewAdaptiveThrottle(limiter, 2, 12)
+	throttle.Step = 1
+	throttle.SuccessesPerStep = 2
+
+	steps := []struct {
+		throttled bool
+		want      rate.Limit
+	}{
+		{false, 10},
+		{false, 11},
+		{false, 11},
+		{false, 12},
+		{false, 12},
+		{false, 12}, // capped at Max
+		{true, 6},
+		{false, 6}, // the run of successes starts over
+		{true, 3},
+		{true, 2}, // floored at Min
+		{false, 2},
+		{false, 3},
+	}
+
+	for i, step := range steps {
+		if step.throttled {
+			throttle.OnThrottled()
+		} else {
+			throttle.OnSuccess()
+		}
+		if got := throttle.Limit(); got != step.want {
+			t.Fatalf("after step %d: limit = %v, want %v", i+1, got, step.want)
+		}
+	}
+}
+
+func TestClientAdaptsRateToThrottledResponses(t *testing.T) {
+	// The first request succeeds, the second one is throttled twice before
+	// succeeding on its last attempt.
+	statuses := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}
+	var requests atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[int(requests.Add(1)-1)%len(statuses)]
+		w.WriteHeader(status)
+		fmt.Fprint(w, cannedPosting)
+	}))
+	client.Limiter = rate.NewLimiter(1000, 1)
+	client.Throttle = NewAdaptiveThrottle(client.Limiter, 1, 2000)
+	client.Throttle.Step = 100
+	client.Throttle.SuccessesPerStep = 1
+
+	if _, err := client.JobPostings(context.Background(), "1"); err != nil {
+		t.Fatalf("JobPostings: %v", err)
+	}
+	if got := client.Throttle.Limit(); got != 1100 {
+		t.Errorf("after a successful response: limit = %v, want 1100", got)
+	}
+
+	if _, err := client.JobPostings(context.Background(), "2"); err != nil {
+		t.Fatalf("JobPostings: %v", err)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Fatalf("sent %d requests, want 4", n)
+	}
+	// Halved by both retried 429s, then raised by the final success
+	if got := client.Throttle.Limit(); got != 375 {
+		t.Errorf("after two throttled attempts: limit = %v, want 375", got)
+	}
+}
@@ -0,0 +1,131 @@
+package linkedin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// titleQualifiers are the parenthesized or bracketed parts of a title,
+	// e.g. "(Remote)" or "[Hybrid]".
+	titleQualifiers = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	// titleSeparators split the role from the location, team or company
+	// appended to it, e.g. "Data Scientist - LATAM | Fintech".
+	titleSeparators = regexp.MustCompile(`\s+[-–—]\s+|\||\s+@\s+`)
+)
+
+// titleNoise are the words dropped from a normalized title: seniority
+// levels, which the analysis extracts on its own, and workplace types.
+var titleNoise = wordSet("sr senior jr junior ssr semisenior semi mid trainee i ii iii iv remote remoto hybrid híbrido hibrido presencial")
+
+// titleAbbreviations are expanded in normalized titles.
+var titleAbbreviations = map[string]string{
+	"eng": "engineer",
+	"dev": "developer",
+	"mgr": "manager",
+}
+
+// NormalizeTitle returns the role of a job title, lowercased and without
+// qualifiers, seniority or whatever follows a separator, so postings of the
+// same role can be grouped, e.g. "Sr. Data Scientist (Remote) - LATAM |
+// Fintech" becomes "data scientist".
+func NormalizeTitle(title string) string {
+	title = strings.ToLower(titleQualifiers.ReplaceAllString(title, " "))
+	role := cleanTitle(titleSeparators.Split(title, 2)[0])
+	if role == "" {
+		// The title starts with a separator or only has noise before it
+		return cleanTitle(title)
+	}
+	return role
+}
+
+// cleanTitle splits title into words, keeping the symbols of names like
+// c++, c# or .net, and drops the noise words.
+func cleanTitle(title string) string {
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#./", r)
+	})
+
+	var kept []string
+	for _, word := range words {
+		word = strings.TrimRight(word, "./")
+		if expanded, ok := titleAbbreviations[word]; ok {
+			word = expanded
+		}
+		if word == "" || titleNoise[word] {
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " ")
+}
+
+// RoleFamily groups the titles containing any of its keywords, as whole
+// words, under Name.
+type RoleFamily struct {
+	Name     string   `json:"family"`
+	Keywords []string `json:"keywords"`
+}
+
+// DefaultRoleFamilies returns the built-in role families. They are tried in
+// order, so the more specific ones come first.
+func DefaultRoleFamilies() []RoleFamily {
+	return []RoleFamily{
+		{Name: "Data Engineering", Keywords: []string{"data engineer", "ingeniero de datos", "etl developer", "big data"}},
+		{Name: "Data Science", Keywords: []string{"data scientist", "data science", "científico de datos", "machine learning", "ml engineer", "ai engineer"}},
+		{Name: "Data Analysis", Keywords: []string{"data analyst", "analista de datos", "business intelligence", "bi analyst", "bi developer"}},
+		{Name: "Security", Keywords: []string{"security", "seguridad", "cybersecurity", "ciberseguridad", "pentester", "soc analyst"}},
+		{Name: "DevOps", Keywords: []string{"devops", "sre", "site reliability", "platform engineer", "cloud engineer", "infrastructure"}},
+		{Name: "QA", Keywords: []string{"qa", "tester", "quality assurance", "test automation"}},
+		{Name: "Mobile", Keywords: []string{"mobile", "android", "ios", "flutter", "react native"}},
+		{Name: "Full Stack", Keywords: []string{"full stack", "fullstack", "full-stack"}},
+		{Name: "Frontend", Keywords: []string{"frontend", "front end", "front-end"}},
+		{Name: "Backend", Keywords: []string{"backend", "back end", "back-end"}},
+		{Name: "Software Engineering", Keywords: []string{"software engineer", "developer", "desarrollador", "programador", "programmer"}},
+	}
+}
+
+// LoadRoleFamilies reads the JSON array of role families at path, e.g.
+// [{"family": "Backend", "keywords": ["backend", "golang developer"]}].
+func LoadRoleFamilies(path string) ([]RoleFamily, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read role families file '%s': %v", path, err)
+	}
+
+	var families []RoleFamily
+	if err := json.Unmarshal(data, &families); err != nil {
+		return nil, fmt.Errorf("could not decode role families file '%s': %v", path, err)
+	}
+
+	for _, family := range families {
+		if family.Name == "" || len(family.Keywords) == 0 {
+			return nil, fmt.Errorf("invalid role families file '%s': every family needs a name and keywords", path)
+		}
+	}
+
+	return families, nil
+}
+
+// ClassifyRole returns the name of the first of families with a keyword in
+// the normalized title, or in the whole title when the normalized one has
+// none, or "" when no family matches.
+func ClassifyRole(title string, families []RoleFamily) string {
+	normalized := NormalizeTitle(title)
+	full := cleanTitle(strings.ToLower(title))
+	for _, candidate := range []string{normalized, full} {
+		padded := " " + candidate + " "
+		for _, family := range families {
+			for _, keyword := range family.Keywords {
+				if keyword = cleanTitle(strings.ToLower(keyword)); keyword != "" && strings.Contains(padded, " "+keyword+" ") {
+					return family.Name
+				}
+			}
+		}
+	}
+	return ""
+}
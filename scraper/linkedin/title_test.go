@@ -0,0 +1,45 @@
+package linkedin
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Sr. Data Scientist (Remote) - LATAM | Fintech", "data scientist"},
+		{"Backend Dev [Híbrido] @ Acme", "backend developer"},
+		{"Ssr C++ Eng II", "c++ engineer"},
+		{"  .NET   Developer  ", ".net developer"},
+		{"Desarrollador Semi Senior – Remoto", "desarrollador"},
+		{"Senior - Golang Developer", "golang developer"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTitle(tt.title); got != tt.want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyRole(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Sr. Data Engineer (Remote) - Fintech", "Data Engineering"},
+		{"Data Analyst | Power BI", "Data Analysis"},
+		{"Full-Stack Developer", "Full Stack"},
+		{"Backend Dev [Hybrid]", "Backend"},
+		{"Ingeniero de Datos Ssr", "Data Engineering"},
+		{"Desarrollador - Equipo de Mobile", "Software Engineering"},
+		{"Acme - Android Developer", "Mobile"},
+		{"Contador Público", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyRole(tt.title, DefaultRoleFamilies()); got != tt.want {
+			t.Errorf("ClassifyRole(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
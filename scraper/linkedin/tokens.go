@@ -0,0 +1,109 @@
+package linkedin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoHealthyTokens is returned when every token of a TokenPool was rejected.
+var ErrNoHealthyTokens = errors.New("no healthy LinkedIn tokens left")
+
+// TokenPool rotates requests across several LinkedIn session tokens (li_at
+// cookies) so a large scrape is spread over more than one session. Tokens that
+// LinkedIn rejects are marked unhealthy and skipped for the rest of the run.
+type TokenPool struct {
+	mu        sync.Mutex
+	tokens    []string
+	unhealthy []bool
+	next      int
+
+	// blocked is set once LinkedIn soft-blocks any of the tokens.
+	blocked bool
+}
+
+// NewTokenPool returns a TokenPool handing out tokens in turn.
+func NewTokenPool(tokens []string) *TokenPool {
+	return &TokenPool{
+		tokens:    tokens,
+		unhealthy: make([]bool, len(tokens)),
+	}
+}
+
+// get returns the next healthy token and its index in the pool.
+func (p *TokenPool) get() (int, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for range p.tokens {
+		i := p.next
+		p.next = (p.next + 1) % len(p.tokens)
+		if !p.unhealthy[i] {
+			return i, p.tokens[i], nil
+		}
+	}
+
+	return 0, "", ErrNoHealthyTokens
+}
+
+// markUnhealthy stops handing out the token at index i. blocked records that
+// it was rejected by LinkedIn's bot detection rather than for being invalid.
+func (p *TokenPool) markUnhealthy(i int, blocked bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.unhealthy[i] = true
+	p.blocked = p.blocked || blocked
+}
+
+// anyBlocked reports whether LinkedIn soft-blocked any token of the pool.
+func (p *TokenPool) anyBlocked() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.blocked
+}
+
+// redact replaces every token of the pool found in s, so it can be logged.
+func (p *TokenPool) redact(s string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, token := range p.tokens {
+		if token != "" {
+			s = strings.ReplaceAll(s, token, "[REDACTED]")
+		}
+	}
+	return s
+}
+
+// LoadTokens reads the comma-separated tokens in envValue and, if tokensFile
+// is set, the tokens in that file, one per line. Blank entries are ignored.
+func LoadTokens(envValue, tokensFile string) ([]string, error) {
+	var tokens []string
+	for _, token := range strings.Split(envValue, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	if tokensFile != "" {
+		data, err := os.ReadFile(tokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read tokens file '%s': %v", tokensFile, err)
+		}
+		for _, token := range strings.Split(string(data), "\n") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("no LinkedIn tokens configured: set LINKEDIN_TOKEN or --tokens-file")
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,74 @@
+package linkedin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLoadTokens(t *testing.T) {
+	tokensFile := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(tokensFile, []byte("file-1\n\n  file-2  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := LoadTokens(" env-1, ,env-2", tokensFile)
+	if err != nil {
+		t.Fatalf("LoadTokens: %v", err)
+	}
+	if want := []string{"env-1", "env-2", "file-1", "file-2"}; !slices.Equal(tokens, want) {
+		t.Errorf("got tokens %q, want %q", tokens, want)
+	}
+
+	if _, err := LoadTokens(" , ", ""); err == nil {
+		t.Error("LoadTokens without tokens succeeded, want an error")
+	}
+	if _, err := LoadTokens("env-1", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadTokens with a missing file succeeded, want an error")
+	}
+}
+
+func TestClientSkipsRejectedTokens(t *testing.T) {
+	var mu sync.Mutex
+	var used []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, _ := r.Cookie("li_at")
+		mu.Lock()
+		used = append(used, cookie.Value)
+		mu.Unlock()
+
+		if cookie.Value == "expired" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	client.Tokens = NewTokenPool([]string{"expired", "valid"})
+
+	for range 2 {
+		if err := client.Check(context.Background()); err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"expired", "valid", "valid"}; !slices.Equal(used, want) {
+		t.Errorf("requests used tokens %q, want %q", used, want)
+	}
+}
+
+func TestClientStopsWhenBlocked(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusLinkedInBlocked)
+	}))
+
+	_, err := client.JobPostings(context.Background(), "4012345678")
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || !errors.Is(err, ErrBlocked999) {
+		t.Errorf("got error %v, want a BlockedError", err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"linkedinScraper/linkedin"
+)
+
+// metrics are the Prometheus series describing a run. A nil *metrics records
+// nothing, so a run without --metrics-addr pays no instrumentation cost.
+type metrics struct {
+	registry *prometheus.Registry
+
+	jobsFetched     *prometheus.CounterVec
+	httpResponses   *prometheus.CounterVec
+	requestDuration prometheus.Histogram
+	rateLimitWait   prometheus.Histogram
+	runDuration     prometheus.Gauge
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		jobsFetched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scraper_jobs_fetched_total",
+			Help: "Job postings fetched from LinkedIn, by category.",
+		}, []string{"category"}),
+		httpResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scraper_http_responses_total",
+			Help: "Responses received from LinkedIn by status code, \"error\" when no response was received.",
+		}, []string{"status"}),
+		requestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "scraper_http_request_duration_seconds",
+			Help:    "Time until LinkedIn answered a request.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		rateLimitWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "scraper_rate_limit_wait_seconds",
+			Help:    "Time requests waited for the rate limiter.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		runDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "scraper_run_duration_seconds",
+			Help: "Time elapsed since the run started.",
+		}),
+	}
+
+	m.registry.MustRegister(m.jobsFetched, m.httpResponses, m.requestDuration, m.rateLimitWait, m.runDuration)
+	return m
+}
+
+// serve exposes the metrics at /metrics on addr in the background.
+func (m *metrics) serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+}
+
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// instrument records the requests and rate limit waits of client.
+func (m *metrics) instrument(client *linkedin.Client) {
+	if m == nil {
+		return
+	}
+
+	client.HTTPClient = &instrumentedDoer{Doer: client.HTTPClient, metrics: m}
+	client.OnRateLimitWait = func(d time.Duration) {
+		m.rateLimitWait.Observe(d.Seconds())
+	}
+}
+
+// trackRunDuration updates the run duration every second until done is closed.
+func (m *metrics) trackRunDuration(start time.Time, done <-chan struct{}) {
+	if m == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			m.runDuration.Set(time.Since(start).Seconds())
+			select {
+			case <-ticker.C:
+			case <-done:
+				m.runDuration.Set(time.Since(start).Seconds())
+				return
+			}
+		}
+	}()
+}
+
+func (m *metrics) jobFetched(category string) {
+	if m == nil {
+		return
+	}
+	m.jobsFetched.WithLabelValues(category).Inc()
+}
+
+// instrumentedDoer records the status and duration of every request it sends.
+type instrumentedDoer struct {
+	linkedin.Doer
+	metrics *metrics
+}
+
+func (d *instrumentedDoer) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := d.Doer.Do(req)
+	d.metrics.requestDuration.Observe(time.Since(start).Seconds())
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	d.metrics.httpResponses.WithLabelValues(status).Inc()
+
+	return resp, err
+}
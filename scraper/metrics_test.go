@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"linkedinScraper/linkedin"
+)
+
+func TestMetricsAfterRun(t *testing.T) {
+	progress, err := openCheckpoint(filepath.Join(t.TempDir(), "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	client := fakeLinkedIn(t, map[string][]string{"golang": {"1", "2", "3"}})
+	client.RetryDelay = time.Millisecond
+	countPostings(client, map[JobID]int{"2": 1})
+	m := newMetrics()
+	m.instrument(client)
+
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang"}}}
+	_, _, err = scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		Progress:     progress,
+		Metrics:      m,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	server := httptest.NewServer(m.handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, series := range []string{
+		`scraper_jobs_fetched_total{category="backend"} 3`,
+		`scraper_http_responses_total{status="200"} 4`,
+		`scraper_http_responses_total{status="503"} 1`,
+		`scraper_http_request_duration_seconds_count 5`,
+		`scraper_rate_limit_wait_seconds_count 5`,
+		`scraper_run_duration_seconds `,
+	} {
+		if !strings.Contains(string(body), series) {
+			t.Errorf("metrics lack %s:\n%s", series, body)
+		}
+	}
+}
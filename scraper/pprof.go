@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/pprof"
+)
+
+// pprofHandler serves the runtime profiles of the scraper under
+// /debug/pprof/, e.g. /debug/pprof/heap or /debug/pprof/goroutine?debug=1.
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// servePprof exposes pprofHandler on addr in the background.
+func servePprof(addr string) {
+	go func() {
+		if err := http.ListenAndServe(addr, pprofHandler()); err != nil {
+			log.Printf("pprof server stopped: %v", err)
+		}
+	}()
+}
@@ -4,29 +4,32 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/time/rate"
-	_ "modernc.org/sqlite"
-)
 
-const geoIdArgentina = "100446943"
+	"linkedinScraper/linkedin"
+	"linkedinScraper/sqlitedb"
+)
 
-type JobID = string
-
-type JobPosting struct {
-	JobID       string `json:"job_id"`
-	Company     string `json:"company"`
-	Description string `json:"description"`
-	Title       string `json:"title"`
-}
+// JobID and JobPosting are the types of the linkedin package, aliased since
+// most of the scraper deals with them.
+type JobID = linkedin.JobID
+type JobPosting = linkedin.JobPosting
 
 type SearchGroup struct {
 	SearchTerm string        `json:"search_term"`
@@ -38,6 +41,23 @@ type JobCategoryGroup struct {
 	Searches []SearchGroup `json:"searches"`
 }
 
+// DedupedJobs is the JSON output written with --dedup-json: every posting is
+// stored once in Jobs and the category/search groups only reference job IDs.
+type DedupedJobs struct {
+	Jobs       map[JobID]*JobPosting     `json:"jobs"`
+	Categories []DedupedJobCategoryGroup `json:"categories"`
+}
+
+type DedupedJobCategoryGroup struct {
+	Category string               `json:"category"`
+	Searches []DedupedSearchGroup `json:"searches"`
+}
+
+type DedupedSearchGroup struct {
+	SearchTerm string  `json:"search_term"`
+	JobIDs     []JobID `json:"job_ids"`
+}
+
 type JobCategory struct {
 	Category    string   `json:"category"`
 	SearchTerms []string `json:"search_terms"`
@@ -56,45 +76,584 @@ func getJobCategories() []JobCategory {
 	}
 }
 
+// filterJobCategories keeps only the categories whose name is in names,
+// ignoring case. Every name must match an existing category.
+func filterJobCategories(categories []JobCategory, names []string) ([]JobCategory, error) {
+	var filtered []JobCategory
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for _, cat := range categories {
+			if strings.EqualFold(cat.Category, name) {
+				filtered = append(filtered, cat)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown category '%s'", name)
+		}
+	}
+
+	return filtered, nil
+}
+
+// postedSince reports whether job was posted at or after cutoff. Jobs without
+// a known posting date are kept, and a zero cutoff keeps every job.
+func postedSince(job *JobPosting, cutoff time.Time) bool {
+	return cutoff.IsZero() || job.PostedAt == nil || !job.PostedAt.Before(cutoff)
+}
+
+// spawn runs f in a new goroutine, or right away in the calling one when
+// sequential is set.
+func spawn(sequential bool, f func()) {
+	if sequential {
+		f()
+		return
+	}
+	go f()
+}
+
+// seededRand returns the source of every random value of a run, so a run
+// can be reproduced by passing the same seed.
+func seededRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), 0))
+}
+
+// searchStartDelay returns how long the search at index, in launch order,
+// waits before sending its first request: index times stagger, jittered by up
+// to half of stagger either way so the starts don't look scheduled. A zero
+// stagger starts every search at once.
+func searchStartDelay(index int, stagger time.Duration, rng *rand.Rand) time.Duration {
+	if stagger <= 0 || index == 0 {
+		return 0
+	}
+	jitter := time.Duration(rng.Int64N(int64(stagger))) - stagger/2
+	return time.Duration(index)*stagger + jitter
+}
+
+// inLocation reports whether the location of job matches pattern. Jobs
+// without a known location are kept, and a nil pattern keeps every job.
+func inLocation(job *JobPosting, pattern *regexp.Regexp) bool {
+	return pattern == nil || job.Location == "" || pattern.MatchString(job.Location)
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "usage: %s <output_file> (must have .json, .sqlite, or .db extension)\n", os.Args[0])
+	jsonOut := flag.String("json-out", "", "JSON file to write the jobs to")
+	sqliteOut := flag.String("sqlite-out", "", "SQLite database to store the jobs in, created if missing. Can be used together with --json-out")
+	since := flag.Duration("since", 0, "only keep jobs posted within this duration, e.g. 48h (0 keeps all). "+
+		"The posting date is only known once a job is fetched, so older jobs are still requested but not saved")
+	maxPerSearch := flag.Int("max-per-search", 0, "maximum number of jobs to fetch per search term (0 means unlimited)")
+	only := flag.String("only", "", "comma-separated list of categories to scrape (default: all)")
+	companiesFile := flag.String("companies", "", "JSON file mapping category names to the LinkedIn numeric ids of companies whose open roles they also include, e.g. {\"Security\": [\"1441\"]}")
+	synonymsFile := flag.String("synonyms", "", "JSON file mapping category names to the search terms they expand to, merged with the built-in ones (categories not built in are added)")
+	compactJSON := flag.Bool("compact", false, "write the JSON output without indentation, about half the size")
+	dedupJSON := flag.Bool("dedup-json", false, "store each posting once in the JSON output and reference it by job ID")
+	tokensFile := flag.String("tokens-file", "", "file with additional LinkedIn tokens, one per line (LINKEDIN_TOKEN may also hold several, comma-separated)")
+	changesOnly := flag.String("changes-only", "", "also write the jobs new to the --sqlite-out database or whose description changed since stored to this JSON file, e.g. changes.json")
+	cacheTTL := flag.Duration("cache-ttl", 0, "reuse the postings stored in the --sqlite-out database when fetched within this duration, e.g. 72h, instead of requesting them again (0 disables it)")
+	refresh := flag.Bool("refresh", false, "fetch every posting from LinkedIn even when --cache-ttl would reuse it")
+	resume := flag.Bool("resume", false, "skip jobs already fetched by an interrupted run with the same output file")
+	postedWithin := flag.Duration("posted-within", 0, "only list jobs LinkedIn reports as posted within this duration, e.g. 168h (0 lists all)")
+	location := flag.String("location", "Argentina", "location to search jobs in, by name (e.g. Argentina, Spain) or numeric LinkedIn geoId")
+	requireLocation := flag.String("require-location", "", "drop jobs whose location doesn't match this case-insensitive regular expression, e.g. Argentina, which LinkedIn sometimes lists outside --location (default: keep all)")
+	workplace := flag.String("workplace", "", "comma-separated workplace types to list: on-site, remote, hybrid (default: all)")
+	lang := flag.String("lang", "", "only keep jobs whose description is detected to be in this language: en or es (default: all)")
+	minDescChars := flag.Int("min-desc-chars", 0, "drop jobs whose description is shorter than this many characters, e.g. external-apply stubs (0 keeps all)")
+	verbose := flag.Bool("verbose", false, "log every LinkedIn request and the response of the failed ones")
+	rawDir := flag.String("raw-dir", "", "directory to store the raw LinkedIn response of every job posting in, as <job_id>.json")
+	jobDeadline := flag.Duration("job-deadline", linkedin.DefaultJobDeadline, "total time to spend fetching a single job, retries included, before abandoning it (0 disables it)")
+	retriesPerMinute := flag.Int("retries-per-minute", 30, "maximum retries of failed requests per minute across the whole run (0 means unlimited)")
+	sequential := flag.Bool("sequential", false, "process categories, searches and jobs one at a time instead of concurrently, for logs that are easy to follow when debugging (still rate limited)")
+	stagger := flag.Duration("stagger", 0, "delay between the start of consecutive searches, jittered by up to half of it, e.g. 2s, to spread the initial burst of requests (0 starts all at once)")
+	requestRate := flag.Float64("rate", 10, "LinkedIn requests per second, the starting rate with --adaptive-rate")
+	adaptiveRate := flag.Bool("adaptive-rate", false, "raise the request rate while LinkedIn answers OK and halve it when it throttles, within --min-rate and --max-rate")
+	minRate := flag.Float64("min-rate", 1, "lowest requests per second with --adaptive-rate")
+	maxRate := flag.Float64("max-rate", 20, "highest requests per second with --adaptive-rate")
+	metricsAddr := flag.String("metrics-addr", "", "address to expose Prometheus metrics on at /metrics, e.g. :9090 (default: disabled)")
+	pprofAddr := flag.String("pprof-addr", "", "address to serve the CPU, heap and goroutine profiles on at /debug/pprof/, e.g. localhost:6060, for diagnosing large scrapes (default: disabled)")
+	extraFieldsFile := flag.String("extra-fields", "", "JSON file mapping extra field names to their JSON Pointer in LinkedIn's job posting response, stored with every job")
+	splitByCategory := flag.Bool("split-by-category", false, "write JSON output as one <category>.json file per category, in a directory named after --json-out without its extension")
+	snapshot := flag.Bool("snapshot", false, "write JSON output to a new timestamped file next to --json-out (e.g. jobs-<time>.json) listed in <json-out>.index.json, instead of overwriting it")
+	nearDupThreshold := flag.Float64("near-dup-threshold", 0, "tag reposts of the same role (same title and company, descriptions at least this similar, 0 to 1) with a shared dedup_group, e.g. 0.8 (0 disables it)")
+	roleFamiliesFile := flag.String("role-families", "", "JSON file with the role families jobs are classified in by title, e.g. [{\"family\": \"Backend\", \"keywords\": [\"backend\"]}], tried in order (default: the built-in ones)")
+	headersFile := flag.String("headers", "", "JSON file mapping HTTP header names to the value sent with every LinkedIn request, over the default browser-like ones (an empty value removes a header)")
+	recordFetchMeta := flag.Bool("fetch-meta", false, "store the attempts, final HTTP status and duration of every job posting fetch in the fetch_meta table of the --sqlite-out database")
+	fetchSkills := flag.Bool("linkedin-skills", false, "also fetch the skills LinkedIn tagged every job with (one more request per job)")
+	seniorityReport := flag.String("seniority-report", "", "only print the seniority breakdown per category of the jobs analyzed in the --sqlite-out database, as json or table, then exit")
+	topCompanies := flag.Int("top-companies", 0, "only print the given number of companies with the most jobs in the --sqlite-out database as json, then exit")
+	searchTermReport := flag.Bool("search-term-report", false, "only print how many jobs each search term surfaced in every run stored in the --sqlite-out database as json, then exit")
+	exportCSVFile := flag.String("export-csv", "", "only write every job in the --sqlite-out database, with its categories, searches and analysis, to this CSV file with one row per job, then exit")
+	seed := flag.Int64("seed", 0, "seed of the random values of the run, e.g. the generated JSESSIONID, logged to reproduce a run (0 picks one from the time)")
+	check := flag.Bool("check", false, "only verify that LinkedIn accepts the configured tokens, then exit")
+	timeout := flag.Duration("timeout", linkedin.DefaultTimeout, "deadline of each LinkedIn request, timed out requests are retried (0 disables it)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] --json-out <file> and/or --sqlite-out <file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 0 {
+		flag.Usage()
 		os.Exit(1)
 	}
-	outputFile := os.Args[1]
 
-	httpClient := &http.Client{}
-	accessToken := os.Getenv("LINKEDIN_TOKEN")
+	if *seniorityReport != "" {
+		if *sqliteOut == "" {
+			log.Fatalf("--seniority-report needs --sqlite-out")
+		}
+
+		db, err := sqlitedb.Open(*sqliteOut)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer db.Close()
+
+		report, err := seniorityDistribution(db)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := writeSeniorityReport(os.Stdout, report, *seniorityReport); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	if *topCompanies > 0 {
+		if *sqliteOut == "" {
+			log.Fatalf("--top-companies needs --sqlite-out")
+		}
+
+		db, err := sqlitedb.Open(*sqliteOut)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer db.Close()
+
+		companies, err := topHiringCompanies(db, *topCompanies)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := writeJSONReport(os.Stdout, companies); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	if *searchTermReport {
+		if *sqliteOut == "" {
+			log.Fatalf("--search-term-report needs --sqlite-out")
+		}
+
+		db, err := sqlitedb.Open(*sqliteOut)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer db.Close()
+
+		counts, err := searchTermCounts(db)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := writeJSONReport(os.Stdout, counts); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	if *exportCSVFile != "" {
+		if *sqliteOut == "" {
+			log.Fatalf("--export-csv needs --sqlite-out")
+		}
+
+		db, err := sqlitedb.Open(*sqliteOut)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer db.Close()
+
+		err = writeFileAtomic(*exportCSVFile, func(w io.Writer) error {
+			return exportCSV(db, w)
+		})
+		if err != nil {
+			log.Fatalf("could not export jobs to CSV: %v", err)
+		}
+		log.Printf("Exported jobs to %s\n", *exportCSVFile)
+		return
+	}
+
+	if !*check {
+		if err := checkOutputs(*jsonOut, *sqliteOut); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	if *splitByCategory && *snapshot {
+		log.Fatalf("--split-by-category and --snapshot can't be used together")
+	}
+	if (*splitByCategory || *snapshot) && *jsonOut == "" {
+		log.Fatalf("--split-by-category and --snapshot need --json-out")
+	}
+	if *changesOnly != "" && *sqliteOut == "" {
+		log.Fatalf("--changes-only needs --sqlite-out")
+	}
+	if *cacheTTL > 0 && *sqliteOut == "" {
+		log.Fatalf("--cache-ttl needs --sqlite-out")
+	}
+	if *recordFetchMeta && *sqliteOut == "" {
+		log.Fatalf("--fetch-meta needs --sqlite-out")
+	}
+
+	httpClient := &http.Client{Timeout: *timeout}
+	accessTokens, err := linkedin.LoadTokens(os.Getenv("LINKEDIN_TOKEN"), *tokensFile)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	limiter := rate.NewLimiter(rate.Limit(*requestRate), 1)
+	client := linkedin.NewClient(httpClient, limiter, linkedin.NewTokenPool(accessTokens))
+	if *adaptiveRate {
+		if *minRate <= 0 || *minRate > *requestRate || *requestRate > *maxRate {
+			log.Fatalf("invalid rates: must satisfy 0 < --min-rate <= --rate <= --max-rate")
+		}
+		client.Throttle = linkedin.NewAdaptiveThrottle(limiter, rate.Limit(*minRate), rate.Limit(*maxRate))
+	}
+	client.Timeout = *timeout
+	client.JobDeadline = *jobDeadline
+	if *retriesPerMinute > 0 {
+		client.RetryBudget = rate.NewLimiter(rate.Every(time.Minute/time.Duration(*retriesPerMinute)), *retriesPerMinute)
+	}
+	client.RawDir = *rawDir
+	client.Verbose = *verbose
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Using random seed %d, pass --seed %d to reproduce the run\n", *seed, *seed)
+	rng := seededRand(*seed)
+	client.CSRFToken = linkedin.NewCSRFToken(rng)
+	if jsessionID := os.Getenv("LINKEDIN_JSESSIONID"); jsessionID != "" {
+		client.CSRFToken = linkedin.ParseCSRFToken(jsessionID)
+	}
+
+	if *extraFieldsFile != "" {
+		client.ExtraFields, err = linkedin.LoadExtraFields(*extraFieldsFile)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	if *headersFile != "" {
+		if err := linkedin.LoadHeaders(*headersFile, client.Headers); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
 
-	limiter := rate.NewLimiter(10, 1)
+	roleFamilies := linkedin.DefaultRoleFamilies()
+	if *roleFamiliesFile != "" {
+		roleFamilies, err = linkedin.LoadRoleFamilies(*roleFamiliesFile)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	if *check {
+		if err := client.Check(context.Background()); err != nil {
+			log.Fatalf("LinkedIn check failed: %v", err)
+		}
+		fmt.Println("LinkedIn check OK")
+		return
+	}
+
+	// The checkpoint is kept next to the SQLite output when there is one,
+	// so runs writing both outputs resume from the same file.
+	checkpointFile := *sqliteOut
+	if checkpointFile == "" {
+		checkpointFile = *jsonOut
+	}
+	progress, err := openCheckpoint(checkpointFile+".progress", *resume)
+	if err != nil {
+		log.Fatalf("could not open checkpoint: %v", err)
+	}
+
+	var cache *jobCache
+	if *cacheTTL > 0 && !*refresh {
+		cache, err = openJobCache(*sqliteOut, *cacheTTL)
+		if err != nil {
+			log.Fatalf("could not open job cache: %v", err)
+		}
+		defer cache.close()
+	}
+
+	var runMetrics *metrics
+	if *metricsAddr != "" {
+		runMetrics = newMetrics()
+		runMetrics.instrument(client)
+		runMetrics.serve(*metricsAddr)
+		log.Printf("Serving metrics on %s/metrics\n", *metricsAddr)
+	}
+	if *pprofAddr != "" {
+		servePprof(*pprofAddr)
+		log.Printf("Serving pprof on %s/debug/pprof/\n", *pprofAddr)
+	}
+	runDone := make(chan struct{})
+	runMetrics.trackRunDuration(time.Now(), runDone)
+
+	if *lang != "" && *lang != linkedin.LanguageEnglish && *lang != linkedin.LanguageSpanish {
+		log.Fatalf("invalid --lang value '%s': must be en or es", *lang)
+	}
+
+	var locationPattern *regexp.Regexp
+	if *requireLocation != "" {
+		locationPattern, err = regexp.Compile("(?i)" + *requireLocation)
+		if err != nil {
+			log.Fatalf("invalid --require-location value '%s': %v", *requireLocation, err)
+		}
+	}
+
+	if *nearDupThreshold < 0 || *nearDupThreshold > 1 {
+		log.Fatalf("invalid --near-dup-threshold value %v: must be between 0 and 1", *nearDupThreshold)
+	}
+
+	geoID, err := linkedin.ResolveGeoID(*location)
+	if err != nil {
+		log.Fatalf("invalid --location value: %v", err)
+	}
+
+	var workplaceTypes []linkedin.WorkplaceType
+	if *workplace != "" {
+		for _, name := range strings.Split(*workplace, ",") {
+			wt, err := linkedin.ParseWorkplaceType(name)
+			if err != nil {
+				log.Fatalf("invalid --workplace value: %v", err)
+			}
+			workplaceTypes = append(workplaceTypes, wt)
+		}
+	}
+
+	var cutoff time.Time
+	if *since > 0 {
+		cutoff = time.Now().Add(-*since)
+	}
 
 	categories := getJobCategories()
-	var jobGroups []JobCategoryGroup
+	if *synonymsFile != "" {
+		synonyms, err := loadSynonyms(*synonymsFile)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		categories = expandJobCategories(categories, synonyms)
+	}
+	if *companiesFile != "" {
+		companies, err := loadCompanies(*companiesFile)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		categories = addCompanySearches(categories, companies)
+	}
+	if *only != "" {
+		categories, err = filterJobCategories(categories, strings.Split(*only, ","))
+		if err != nil {
+			log.Fatalf("invalid --only value: %v", err)
+		}
+	}
+	var fetchMetas *fetchMetaLog
+	if *recordFetchMeta {
+		fetchMetas = &fetchMetaLog{}
+	}
+
+	jobGroups, stats, err := scrapeJobs(context.Background(), client, limiter, categories, scrapeConfig{
+		GeoID:           geoID,
+		PostedWithin:    *postedWithin,
+		WorkplaceTypes:  workplaceTypes,
+		MaxPerSearch:    *maxPerSearch,
+		Cutoff:          cutoff,
+		Lang:            *lang,
+		MinDescChars:    *minDescChars,
+		LocationPattern: locationPattern,
+		RoleFamilies:    roleFamilies,
+		FetchSkills:     *fetchSkills,
+		Sequential:      *sequential,
+		Stagger:         *stagger,
+		Rng:             rng,
+		Progress:        progress,
+		Cache:           cache,
+		FetchMetas:      fetchMetas,
+		Metrics:         runMetrics,
+	})
+	close(runDone)
+	if err != nil {
+		log.Fatalf("aborting run: %v. LinkedIn flagged the session as a bot; wait a few hours before scraping again, "+
+			"and consider lowering the request rate. Fetched jobs are kept in the checkpoint, rerun with --resume.", err)
+	}
+
+	if stats.ShortDescriptions > 0 {
+		log.Printf("Dropped %d jobs with a description shorter than %d characters\n", stats.ShortDescriptions, *minDescChars)
+	}
+
+	if stats.OtherLocations > 0 {
+		log.Printf("Warning: dropped %d jobs located outside --require-location %q\n", stats.OtherLocations, *requireLocation)
+	}
+
+	if *nearDupThreshold > 0 {
+		assignNearDuplicateGroups(jobGroups, *nearDupThreshold)
+	}
+
+	// Both outputs get the same jobGroups
+	switch {
+	case *jsonOut == "":
+	case *splitByCategory:
+		dir := strings.TrimSuffix(*jsonOut, filepath.Ext(*jsonOut))
+		if err := saveJobsByCategory(jobGroups, dir, *dedupJSON, *compactJSON); err != nil {
+			log.Fatalf("could not save jobs by category: %v", err)
+		}
+	case *snapshot:
+		path, err := saveSnapshot(jobGroups, *jsonOut, time.Now(), *dedupJSON, *compactJSON)
+		if err != nil {
+			log.Fatalf("could not save snapshot: %v", err)
+		}
+		log.Printf("Saved snapshot %s\n", path)
+	default:
+		if err := saveJobsToFile(jobGroups, *jsonOut, *dedupJSON, *compactJSON); err != nil {
+			log.Fatalf("could not save jobs to file: %v", err)
+		}
+	}
+
+	if *sqliteOut != "" {
+		fatalWithFallback := func(format string, err error) {
+			logSQLiteFailure(log.Default(), jobGroups, *sqliteOut, time.Now(), format, err)
+			os.Exit(1)
+		}
+
+		if *changesOnly != "" {
+			changes, err := saveJobChanges(jobGroups, *sqliteOut, *changesOnly, *compactJSON)
+			if err != nil {
+				fatalWithFallback("could not save job changes: %v", err)
+			}
+			log.Printf("Saved %d new and %d changed jobs to %s\n", len(changes.New), len(changes.Changed), *changesOnly)
+		}
+		if err := saveJobsToSQLite(jobGroups, *sqliteOut, time.Now()); err != nil {
+			fatalWithFallback("could not save jobs to SQLite: %v", err)
+		}
+		if fetchMetas != nil {
+			if err := fetchMetas.save(*sqliteOut); err != nil {
+				log.Fatalf("could not save fetch metadata: %v", err)
+			}
+		}
+	}
+
+	if err := progress.remove(); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// checkOutputs returns an error when neither a JSON file nor a SQLite
+// database was given to write the jobs to.
+func checkOutputs(jsonOut, sqliteOut string) error {
+	if jsonOut == "" && sqliteOut == "" {
+		return errors.New("no output given: set --json-out, --sqlite-out or both")
+	}
+	return nil
+}
+
+// scrapeConfig holds the options of a scrape, set from the command line.
+type scrapeConfig struct {
+	GeoID          string
+	PostedWithin   time.Duration
+	WorkplaceTypes []linkedin.WorkplaceType
+	MaxPerSearch   int
+	// Cutoff drops the jobs posted before it, unless it is zero.
+	Cutoff          time.Time
+	Lang            string
+	MinDescChars    int
+	LocationPattern *regexp.Regexp
+	RoleFamilies    []linkedin.RoleFamily
+	FetchSkills     bool
+	Sequential      bool
+	Stagger         time.Duration
+	Rng             *rand.Rand
+	Progress        *checkpoint
+	// Cache, FetchMetas and Metrics may be nil.
+	Cache      *jobCache
+	FetchMetas *fetchMetaLog
+	Metrics    *metrics
+}
+
+// scrapeStats counts the jobs a scrape dropped.
+type scrapeStats struct {
+	ShortDescriptions int64
+	OtherLocations    int64
+}
+
+// scrapeJobs lists the jobs of every search term of categories and fetches
+// their postings with client, grouped by category and search. It stops early
+// and returns the error when LinkedIn blocks the scraper.
+func scrapeJobs(ctx context.Context, client *linkedin.Client, limiter *rate.Limiter, categories []JobCategory, cfg scrapeConfig) ([]JobCategoryGroup, scrapeStats, error) {
+	var shortDescriptions atomic.Int64
+	var otherLocations atomic.Int64
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 
-	// Initialize jobGroups with categories and empty search groups
-	for _, cat := range categories {
-		jobGroup := JobCategoryGroup{
-			Category: cat.Category,
-			Searches: make([]SearchGroup, 0, len(cat.SearchTerms)),
+	// ctx is canceled as soon as LinkedIn blocks the scraper, so every
+	// pending request stops instead of making the block worse.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	abortIfBlocked := func(err error) {
+		var blocked *linkedin.BlockedError
+		if errors.As(err, &blocked) {
+			cancel(err)
 		}
-		mu.Lock()
-		jobGroups = append(jobGroups, jobGroup)
-		mu.Unlock()
 	}
 
-	// Process all categories and search terms concurrently
-	for _, cat := range categories {
-		for _, searchTerm := range cat.SearchTerms {
+	// Every search writes its group to its own slot, so no lock is needed,
+	// and the groups are collected in the configured order once all are done.
+	searchGroups := make([][]SearchGroup, len(categories))
+	for i, cat := range categories {
+		searchGroups[i] = make([]SearchGroup, len(cat.SearchTerms))
+	}
+
+	// Process all categories and search terms concurrently, each search
+	// starting --stagger after the previous one, or one at a time with
+	// --sequential
+	launched := 0
+	for i, cat := range categories {
+		for j, searchTerm := range cat.SearchTerms {
+			// Sequential searches are spaced by the ones before them
+			var startDelay time.Duration
+			if !cfg.Sequential {
+				startDelay = searchStartDelay(launched, cfg.Stagger, cfg.Rng)
+			}
+			launched++
+
+			category, slot := cat.Category, &searchGroups[i][j]
 			wg.Add(1)
-			go func(category, searchTerm string) {
+			spawn(cfg.Sequential, func() {
 				defer wg.Done()
 
-				limiter.Wait(context.TODO())
+				if startDelay > 0 {
+					select {
+					case <-time.After(startDelay):
+					case <-ctx.Done():
+						return
+					}
+				}
+				if err := limiter.Wait(ctx); err != nil {
+					return
+				}
 				log.Printf("Fetching job listings for category %s, search: %s\n", category, searchTerm)
 
-				listings := jobListings(httpClient, limiter, accessToken, searchTerm)
+				// listingsCtx stops paginating once --max-per-search is reached
+				listingsCtx, cancelListings := context.WithCancel(ctx)
+				defer cancelListings()
+
+				opts := searchOptions(searchTerm)
+				opts.GeoID = cfg.GeoID
+				opts.PostedWithin = cfg.PostedWithin
+				opts.WorkplaceTypes = cfg.WorkplaceTypes
+				listings, listingsErr := client.JobListings(listingsCtx, opts)
 				searchGroup := SearchGroup{
 					SearchTerm: searchTerm,
 					Jobs:       make([]*JobPosting, 0),
@@ -103,61 +662,144 @@ func main() {
 				var searchWg sync.WaitGroup
 				var searchMu sync.Mutex
 
+				listed := 0
 				for jid := range listings {
+					if cfg.MaxPerSearch > 0 && listed >= cfg.MaxPerSearch {
+						// Keep draining until the producer notices the
+						// cancellation and closes the channel.
+						cancelListings()
+						continue
+					}
+					listed++
+
 					searchWg.Add(1)
-					go func(jid JobID) {
+					spawn(cfg.Sequential, func() {
 						defer searchWg.Done()
 
-						limiter.Wait(context.TODO())
-						log.Printf("Fetching data for job %s (category: %s, search: %s)\n", jid, category, searchTerm)
+						job, ok := cfg.Progress.lookup(category, searchTerm, jid)
+						if ok {
+							log.Printf("Skipping job %s already fetched (category: %s, search: %s)\n", jid, category, searchTerm)
+						} else if job, ok = cfg.Cache.lookup(jid); ok {
+							log.Printf("Reusing job %s fetched at %v (category: %s, search: %s)\n", jid, job.FetchedAt, category, searchTerm)
+						} else {
+							if err := limiter.Wait(ctx); err != nil {
+								return
+							}
+							log.Printf("Fetching data for job %s (category: %s, search: %s)\n", jid, category, searchTerm)
 
-						job, err := jobPostings(httpClient, limiter, jid, accessToken)
-						if err != nil {
-							log.Printf("could not get job posting for job %s: %v", jid, err)
+							var stats linkedin.FetchStats
+							start := time.Now()
+							var err error
+							job, err = client.JobPostings(linkedin.WithFetchStats(ctx, &stats), jid)
+							meta := fetchMeta{JobID: jid, FetchedAt: start.UTC(), Attempts: stats.Attempts, Status: stats.Status, Duration: time.Since(start)}
+							if err != nil {
+								meta.Err = err.Error()
+								cfg.FetchMetas.record(meta)
+								log.Printf("could not get job posting for job %s after %d attempts: %v", jid, stats.Attempts, err)
+								abortIfBlocked(err)
+								return
+							}
+							cfg.FetchMetas.record(meta)
+							if stats.Attempts > 1 {
+								log.Printf("Fetched job %s after %d attempts in %v\n", jid, stats.Attempts, meta.Duration.Round(time.Millisecond))
+							}
+							fetchedAt := time.Now().UTC()
+							job.FetchedAt = &fetchedAt
+
+							cfg.Metrics.jobFetched(category)
+
+							if cfg.FetchSkills {
+								if err := limiter.Wait(ctx); err != nil {
+									return
+								}
+								skills, err := client.JobSkills(ctx, jid)
+								if err != nil {
+									log.Printf("could not get LinkedIn skills for job %s: %v", jid, err)
+									abortIfBlocked(err)
+								}
+								job.LinkedInSkills = skills
+							}
+
+							if err := cfg.Progress.record(category, searchTerm, job); err != nil {
+								log.Printf("%v", err)
+							}
+						}
+
+						job.NormalizedTitle = linkedin.NormalizeTitle(job.Title)
+						job.RoleFamily = linkedin.ClassifyRole(job.Title, cfg.RoleFamilies)
+
+						if !postedSince(job, cfg.Cutoff) {
+							log.Printf("Dropping job %s posted at %v, before %v\n", jid, job.PostedAt, cfg.Cutoff)
+							return
+						}
+
+						if cfg.Lang != "" && job.Language != cfg.Lang {
+							log.Printf("Dropping job %s in language %q, not %q\n", jid, job.Language, cfg.Lang)
+							return
+						}
+
+						if utf8.RuneCountInString(job.Description) < cfg.MinDescChars {
+							log.Printf("Dropping job %s with a description shorter than %d characters\n", jid, cfg.MinDescChars)
+							shortDescriptions.Add(1)
+							return
+						}
+
+						if !inLocation(job, cfg.LocationPattern) {
+							log.Printf("Dropping job %s located in %q, not matching --require-location\n", jid, job.Location)
+							otherLocations.Add(1)
 							return
 						}
 
 						searchMu.Lock()
 						searchGroup.Jobs = append(searchGroup.Jobs, job)
 						searchMu.Unlock()
-					}(jid)
+					})
 				}
 
 				searchWg.Wait()
 
-				if len(searchGroup.Jobs) > 0 {
-					mu.Lock()
-					for i, jobGroup := range jobGroups {
-						if jobGroup.Category == category {
-							jobGroups[i].Searches = append(jobGroups[i].Searches, searchGroup)
-							break
-						}
-					}
-					mu.Unlock()
+				if err := <-listingsErr; err != nil && listingsCtx.Err() == nil {
+					log.Printf("could not get job listings for search %s: %v", searchTerm, err)
+					abortIfBlocked(err)
 				}
-			}(cat.Category, searchTerm)
+
+				*slot = searchGroup
+			})
 		}
 	}
 
 	wg.Wait()
 
-	// Determine storage based on file extension
-	ext := strings.ToLower(filepath.Ext(outputFile))
-	switch ext {
-	case ".json":
-		if err := saveJobsToFile(jobGroups, outputFile); err != nil {
-			log.Fatal("could not save jobs to file: %v", err)
+	if cause := context.Cause(ctx); cause != nil {
+		return nil, scrapeStats{}, cause
+	}
+
+	stats := scrapeStats{ShortDescriptions: shortDescriptions.Load(), OtherLocations: otherLocations.Load()}
+	return collectJobGroups(categories, searchGroups), stats, nil
+}
+
+// collectJobGroups groups the searches of every category, searchGroups[i]
+// holding those of categories[i], dropping the searches with no jobs.
+func collectJobGroups(categories []JobCategory, searchGroups [][]SearchGroup) []JobCategoryGroup {
+	jobGroups := make([]JobCategoryGroup, 0, len(categories))
+	for i, cat := range categories {
+		jobGroup := JobCategoryGroup{
+			Category: cat.Category,
+			Searches: make([]SearchGroup, 0, len(searchGroups[i])),
 		}
-	case ".sqlite", ".db":
-		if err := saveJobsToSQLite(jobGroups, outputFile); err != nil {
-			log.Fatal("could not save jobs to SQLite: %v", err)
+		for _, searchGroup := range searchGroups[i] {
+			if len(searchGroup.Jobs) > 0 {
+				jobGroup.Searches = append(jobGroup.Searches, searchGroup)
+			}
 		}
-	default:
-		log.Fatal("unsupported file extension: must be .json, .sqlite, or .db")
+		jobGroups = append(jobGroups, jobGroup)
 	}
+	return jobGroups
 }
 
-func saveJobsToFile(jobGroups []JobCategoryGroup, jobsFilePath string) error {
+// saveJobsToFile writes jobGroups to jobsFilePath as JSON, indented unless
+// compact is set.
+func saveJobsToFile(jobGroups []JobCategoryGroup, jobsFilePath string, dedup, compact bool) error {
 	dir := filepath.Dir(jobsFilePath)
 	if _, err := os.Stat(dir); err != nil {
 		if err := os.MkdirAll(dir, 0700); err != nil {
@@ -165,71 +807,169 @@ func saveJobsToFile(jobGroups []JobCategoryGroup, jobsFilePath string) error {
 		}
 	}
 
-	f, err := os.OpenFile(jobsFilePath, os.O_RDWR|os.O_CREATE, 0600)
-	if err != nil {
-		return fmt.Errorf("could not open file '%s': %v", jobsFilePath, err)
+	var output any = jobGroups
+	if dedup {
+		output = dedupJobGroups(jobGroups)
 	}
-	defer f.Close()
 
-	if err := json.NewEncoder(f).Encode(jobGroups); err != nil {
-		return fmt.Errorf("could not encode jobs to json: %v", err)
+	return writeFileAtomic(jobsFilePath, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		if !compact {
+			enc.SetIndent("", "  ")
+		}
+		if err := enc.Encode(output); err != nil {
+			return fmt.Errorf("could not encode jobs to json: %v", err)
+		}
+		return nil
+	})
+}
+
+// logSQLiteFailure logs err, formatted with format, after saving jobGroups to
+// the fallback JSON file of sqliteFile: the jobs may be hours of work, so they
+// are dumped to JSON when the database can't take them.
+func logSQLiteFailure(logger *log.Logger, jobGroups []JobCategoryGroup, sqliteFile string, now time.Time, format string, err error) {
+	path, fallbackErr := saveSQLiteFallback(jobGroups, sqliteFile, now)
+	if fallbackErr != nil {
+		logger.Printf(format+". The jobs could not be saved to a fallback JSON file either: %v", err, fallbackErr)
+		return
 	}
+	logger.Printf(format+". The jobs were saved to %s instead so they are not lost", err, path)
+}
+
+// saveSQLiteFallback writes jobGroups, in the format of saveJobsToFile, next
+// to sqliteFile as <name>-fallback-<time>.json, or to the temporary directory
+// when that directory isn't writable either. It returns the path written.
+func saveSQLiteFallback(jobGroups []JobCategoryGroup, sqliteFile string, now time.Time) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(sqliteFile), filepath.Ext(sqliteFile)) +
+		"-fallback-" + now.UTC().Format(snapshotTimeFormat) + ".json"
+
+	var errs []error
+	for _, dir := range []string{filepath.Dir(sqliteFile), os.TempDir()} {
+		path := filepath.Join(dir, name)
+		err := saveJobsToFile(jobGroups, path, false, false)
+		if err == nil {
+			return path, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", errors.Join(errs...)
+}
 
+// saveJobsByCategory writes every category of jobGroups to its own
+// <dir>/<category>.json file, in the same format as saveJobsToFile.
+func saveJobsByCategory(jobGroups []JobCategoryGroup, dir string, dedup, compact bool) error {
+	used := make(map[string]bool)
+	for _, jobGroup := range jobGroups {
+		name := categoryFileName(jobGroup.Category)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", categoryFileName(jobGroup.Category), i)
+		}
+		used[name] = true
+
+		path := filepath.Join(dir, name+".json")
+		if err := saveJobsToFile([]JobCategoryGroup{jobGroup}, path, dedup, compact); err != nil {
+			return fmt.Errorf("could not save category '%s': %v", jobGroup.Category, err)
+		}
+	}
 	return nil
 }
 
-func saveJobsToSQLite(jobGroups []JobCategoryGroup, sqliteFile string) error {
-	db, err := sql.Open("sqlite", sqliteFile)
+// categoryFileName turns a category name into a safe file name, keeping only
+// lowercase letters, digits and single dashes.
+func categoryFileName(category string) string {
+	var name strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(category) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			name.WriteRune(r)
+			dash = false
+		} else if !dash && name.Len() > 0 {
+			name.WriteByte('-')
+			dash = true
+		}
+	}
+
+	if s := strings.TrimSuffix(name.String(), "-"); s != "" {
+		return s
+	}
+	return "category"
+}
+
+// writeFileAtomic writes the file at path through a temporary file in the same
+// directory that is renamed over path once complete, so an interrupted write
+// never leaves a truncated or partially overwritten file behind.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("could not open SQLite database '%s': %v", sqliteFile, err)
+		return fmt.Errorf("could not create temporary file for '%s': %v", path, err)
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("could not sync file '%s': %v", tmpPath, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not close file '%s': %v", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("could not rename '%s' to '%s': %v", tmpPath, path, err)
 	}
-	defer db.Close()
 
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return fmt.Errorf("could not enable foreign keys: %v", err)
-	}
-
-	// Create tables
-	createTables := []string{
-		`CREATE TABLE IF NOT EXISTS jobs (
-			job_id TEXT PRIMARY KEY,
-			company TEXT NOT NULL,
-			description TEXT NOT NULL,
-			title TEXT NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS categories (
-			category_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			category_name TEXT NOT NULL UNIQUE
-		)`,
-		`CREATE TABLE IF NOT EXISTS jobs_categories (
-			job_id TEXT,
-			category_id INTEGER,
-			PRIMARY KEY (job_id, category_id),
-			FOREIGN KEY (job_id) REFERENCES jobs(job_id),
-			FOREIGN KEY (category_id) REFERENCES categories(category_id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS searches (
-			search_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			search_term TEXT NOT NULL UNIQUE
-		)`,
-		`CREATE TABLE IF NOT EXISTS searches_jobs (
-			search_id INTEGER,
-			job_id TEXT,
-			first_seen TEXT NOT NULL,
-			last_seen TEXT NOT NULL,
-			PRIMARY KEY (search_id, job_id),
-			FOREIGN KEY (search_id) REFERENCES searches(search_id),
-			FOREIGN KEY (job_id) REFERENCES jobs(job_id)
-		)`,
-	}
-
-	for _, query := range createTables {
-		if _, err := db.Exec(query); err != nil {
-			return fmt.Errorf("could not create table: %v", err)
+	return nil
+}
+
+// dedupJobGroups moves the postings out of jobGroups into a single map keyed
+// by job ID, so a job found by several searches is only serialized once.
+func dedupJobGroups(jobGroups []JobCategoryGroup) DedupedJobs {
+	deduped := DedupedJobs{
+		Jobs:       make(map[JobID]*JobPosting),
+		Categories: make([]DedupedJobCategoryGroup, 0, len(jobGroups)),
+	}
+
+	for _, jobGroup := range jobGroups {
+		category := DedupedJobCategoryGroup{
+			Category: jobGroup.Category,
+			Searches: make([]DedupedSearchGroup, 0, len(jobGroup.Searches)),
 		}
+
+		for _, searchGroup := range jobGroup.Searches {
+			search := DedupedSearchGroup{
+				SearchTerm: searchGroup.SearchTerm,
+				JobIDs:     make([]JobID, 0, len(searchGroup.Jobs)),
+			}
+
+			for _, job := range searchGroup.Jobs {
+				deduped.Jobs[job.JobID] = job
+				search.JobIDs = append(search.JobIDs, job.JobID)
+			}
+
+			category.Searches = append(category.Searches, search)
+		}
+
+		deduped.Categories = append(deduped.Categories, category)
 	}
 
+	return deduped
+}
+
+// saveJobsToSQLite saves jobGroups as the run at runAt, which is the last_seen
+// of every search-job pair and the first_seen of the new ones.
+func saveJobsToSQLite(jobGroups []JobCategoryGroup, sqliteFile string, runAt time.Time) error {
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
 	// Begin transaction
 	tx, err := db.Begin()
 	if err != nil {
@@ -237,8 +977,14 @@ func saveJobsToSQLite(jobGroups []JobCategoryGroup, sqliteFile string) error {
 	}
 	defer tx.Rollback()
 
-	// Get execution timestamp
-	timestamp := time.Now().Format(time.RFC3339)
+	timestamp := runAt.Format(time.RFC3339)
+
+	// Record the run, to keep the number of jobs each search surfaced
+	var runID int64
+	err = tx.QueryRow(`INSERT INTO scrape_runs (run_at) VALUES (?) RETURNING run_id`, timestamp).Scan(&runID)
+	if err != nil {
+		return fmt.Errorf("could not insert scrape run: %v", err)
+	}
 
 	// Insert data
 	for _, jobGroup := range jobGroups {
@@ -263,14 +1009,16 @@ func saveJobsToSQLite(jobGroups []JobCategoryGroup, sqliteFile string) error {
 				return fmt.Errorf("could not insert/get search term '%s': %v", searchGroup.SearchTerm, err)
 			}
 
+			_, err = tx.Exec(`
+				INSERT INTO search_results (run_id, category, search_term, job_count)
+				VALUES (?, ?, ?, ?)`, runID, jobGroup.Category, searchGroup.SearchTerm, len(searchGroup.Jobs))
+			if err != nil {
+				return fmt.Errorf("could not insert search results of '%s': %v", searchGroup.SearchTerm, err)
+			}
+
 			for _, job := range searchGroup.Jobs {
-				// Insert job if not exists
-				_, err = tx.Exec(`
-					INSERT OR IGNORE INTO jobs (job_id, company, description, title)
-					VALUES (?, ?, ?, ?)`,
-					job.JobID, job.Company, job.Description, job.Title)
-				if err != nil {
-					return fmt.Errorf("could not insert job '%s': %v", job.JobID, err)
+				if err := sqlitedb.SaveJob(tx, job); err != nil {
+					return err
 				}
 
 				// Insert job-category relationship
@@ -285,8 +1033,8 @@ func saveJobsToSQLite(jobGroups []JobCategoryGroup, sqliteFile string) error {
 				_, err = tx.Exec(`
 					INSERT INTO searches_jobs (search_id, job_id, first_seen, last_seen)
 					VALUES (?, ?, ?, ?)
-					ON CONFLICT(search_id, job_id) DO UPDATE SET last_seen = ?`,
-					searchID, job.JobID, timestamp, timestamp, timestamp)
+					ON CONFLICT(search_id, job_id) DO UPDATE SET last_seen = excluded.last_seen`,
+					searchID, job.JobID, timestamp, timestamp)
 				if err != nil {
 					return fmt.Errorf("could not insert/update search-job relationship for search '%d' and job '%s': %v", searchID, job.JobID, err)
 				}
@@ -300,3 +1048,91 @@ func saveJobsToSQLite(jobGroups []JobCategoryGroup, sqliteFile string) error {
 
 	return nil
 }
+
+// CompanyCount is the number of distinct jobs stored for a company.
+type CompanyCount struct {
+	Company string `json:"company"`
+	Jobs    int    `json:"jobs"`
+}
+
+// topHiringCompanies returns the n companies with the most jobs in db.
+func topHiringCompanies(db *sql.DB, n int) ([]CompanyCount, error) {
+	rows, err := db.Query(`
+		SELECT c.company_name, COUNT(j.job_id) AS jobs
+		FROM companies c
+		JOIN jobs j ON j.company_id = c.company_id
+		GROUP BY c.company_id
+		ORDER BY jobs DESC, c.company_name
+		LIMIT ?`, n)
+	if err != nil {
+		return nil, fmt.Errorf("could not query top hiring companies: %v", err)
+	}
+	defer rows.Close()
+
+	counts := []CompanyCount{}
+	for rows.Next() {
+		var count CompanyCount
+		if err := rows.Scan(&count.Company, &count.Jobs); err != nil {
+			return nil, fmt.Errorf("could not read top hiring companies: %v", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read top hiring companies: %v", err)
+	}
+
+	return counts, nil
+}
+
+// writeJSONReport writes report to w as indented JSON, for the reports printed
+// by the flags that only query the --sqlite-out database.
+func writeJSONReport(w io.Writer, report any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("could not encode report: %v", err)
+	}
+	return nil
+}
+
+// SearchTermCount is the number of jobs a search term surfaced in a run.
+type SearchTermCount struct {
+	RunAt      string `json:"run_at"`
+	Category   string `json:"category"`
+	SearchTerm string `json:"search_term"`
+	Jobs       int    `json:"jobs"`
+}
+
+// searchTermCounts returns how many jobs each search term surfaced in every
+// run stored in db, oldest run first.
+func searchTermCounts(db *sql.DB) ([]SearchTermCount, error) {
+	rows, err := db.Query(`
+		SELECT r.run_at, s.category, s.search_term, s.job_count
+		FROM search_results s
+		JOIN scrape_runs r ON r.run_id = s.run_id
+		ORDER BY r.run_id, s.category, s.search_term`)
+	if err != nil {
+		return nil, fmt.Errorf("could not query search term counts: %v", err)
+	}
+	defer rows.Close()
+
+	counts := []SearchTermCount{}
+	for rows.Next() {
+		var count SearchTermCount
+		if err := rows.Scan(&count.RunAt, &count.Category, &count.SearchTerm, &count.Jobs); err != nil {
+			return nil, fmt.Errorf("could not read search term counts: %v", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read search term counts: %v", err)
+	}
+
+	return counts, nil
+}
+
+// nullString stores empty strings as NULL, for columns LinkedIn does not
+// always provide.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
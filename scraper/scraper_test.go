@@ -0,0 +1,967 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"linkedinScraper/linkedin"
+	"linkedinScraper/sqlitedb"
+)
+
+// testJobGroups returns two categories sharing a job, as the scraper groups
+// the postings it fetched.
+func testJobGroups() []JobCategoryGroup {
+	postedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	backend := &JobPosting{
+		JobID:       "1",
+		Company:     "Acme",
+		Title:       "Backend Developer",
+		Description: "Go developer\nwith \"quotes\"",
+		PostedAt:    &postedAt,
+		Location:    "Buenos Aires, Argentina",
+		Language:    "en",
+	}
+	data := &JobPosting{JobID: "2", Company: "Globex", Title: "Data Engineer", Description: "Spark"}
+
+	return []JobCategoryGroup{
+		{Category: "backend", Searches: []SearchGroup{{SearchTerm: "golang", Jobs: []*JobPosting{backend}}}},
+		{Category: "data", Searches: []SearchGroup{
+			{SearchTerm: "spark", Jobs: []*JobPosting{data}},
+			{SearchTerm: "golang", Jobs: []*JobPosting{backend, data}},
+		}},
+	}
+}
+
+func TestSaveJobsToFile(t *testing.T) {
+	jobGroups := testJobGroups()
+
+	for _, compact := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "jobs", "jobs.json")
+		if err := saveJobsToFile(jobGroups, path, false, compact); err != nil {
+			t.Fatalf("saveJobsToFile: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines := bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+		if compact && lines != 0 {
+			t.Errorf("compact output has %d newlines besides the trailing one", lines)
+		}
+		if !compact && lines == 0 {
+			t.Error("default output is not indented")
+		}
+
+		var decoded []JobCategoryGroup
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("could not decode output (compact %v): %v", compact, err)
+		}
+		if !reflect.DeepEqual(decoded, jobGroups) {
+			t.Errorf("output (compact %v) decodes to %+v, want %+v", compact, decoded, jobGroups)
+		}
+	}
+}
+
+func TestSaveJobsToFileOverwritesLargerFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "jobs.json")
+
+	large := testJobGroups()
+	large[0].Searches[0].Jobs[0].Description = strings.Repeat("Go developer. ", 1000)
+	if err := saveJobsToFile(large, path, false, false); err != nil {
+		t.Fatalf("saveJobsToFile: %v", err)
+	}
+	small := []JobCategoryGroup{{Category: "data", Searches: []SearchGroup{{SearchTerm: "spark", Jobs: []*JobPosting{{JobID: "2", Description: "Spark"}}}}}}
+	if err := saveJobsToFile(small, path, false, false); err != nil {
+		t.Fatalf("saveJobsToFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []JobCategoryGroup
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("could not decode the overwritten file: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, small) {
+		t.Errorf("overwritten file decodes to %+v, want %+v", decoded, small)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only jobs.json without temporary files", len(entries))
+	}
+}
+
+func TestSaveJobsToFileDedup(t *testing.T) {
+	jobGroups := testJobGroups()
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	if err := saveJobsToFile(jobGroups, path, true, false); err != nil {
+		t.Fatalf("saveJobsToFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, description := range []string{"Go developer\nwith \"quotes\"", "Spark"} {
+		encoded, _ := json.Marshal(description)
+		if n := bytes.Count(data, encoded); n != 1 {
+			t.Errorf("output holds description %s %d times, want once", encoded, n)
+		}
+	}
+
+	var deduped DedupedJobs
+	if err := json.Unmarshal(data, &deduped); err != nil {
+		t.Fatalf("could not decode output: %v", err)
+	}
+	if len(deduped.Jobs) != 2 {
+		t.Errorf("got %d jobs, want 2", len(deduped.Jobs))
+	}
+	if got := deduped.Categories[1].Searches[1].JobIDs; !slices.Equal(got, []JobID{"1", "2"}) {
+		t.Errorf("search golang of category data lists jobs %q, want 1 and 2", got)
+	}
+}
+
+func TestSaveJobsByCategory(t *testing.T) {
+	dir := t.TempDir()
+	jobGroups := testJobGroups()
+	jobGroups[1].Category = "Data & Analytics"
+	if err := saveJobsByCategory(jobGroups, dir, false, false); err != nil {
+		t.Fatalf("saveJobsByCategory: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"backend.json", "data-analytics.json"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got files %v, want %v", names, want)
+	}
+
+	for i, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded []JobCategoryGroup
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("could not decode %s: %v", name, err)
+		}
+		if want := jobGroups[i : i+1]; !reflect.DeepEqual(decoded, want) {
+			t.Errorf("%s decodes to %+v, want %+v", name, decoded, want)
+		}
+	}
+}
+
+func TestCategoryFileName(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"backend", "backend"},
+		{"Data & Analytics", "data-analytics"},
+		{"  QA / Testing  ", "qa-testing"},
+		{"!!!", "category"},
+	}
+
+	for _, tt := range tests {
+		if got := categoryFileName(tt.category); got != tt.want {
+			t.Errorf("categoryFileName(%q) = %q, want %q", tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    bool
+		sqlite  bool
+		wantErr bool
+	}{
+		{"json only", true, false, false},
+		{"sqlite only", false, true, false},
+		{"both", true, true, false},
+		{"neither", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var jsonOut, sqliteOut string
+			if tt.json {
+				jsonOut = filepath.Join(dir, "jobs.json")
+			}
+			if tt.sqlite {
+				sqliteOut = filepath.Join(dir, "jobs.db")
+			}
+
+			err := checkOutputs(jsonOut, sqliteOut)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			jobGroups := testJobGroups()
+			if jsonOut != "" {
+				if err := saveJobsToFile(jobGroups, jsonOut, false, false); err != nil {
+					t.Fatalf("saveJobsToFile: %v", err)
+				}
+				data, err := os.ReadFile(jsonOut)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var decoded []JobCategoryGroup
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("could not decode %s: %v", jsonOut, err)
+				}
+				if got, want := jobIDs(decoded), jobIDs(jobGroups); !reflect.DeepEqual(got, want) {
+					t.Errorf("got jobs %q in the JSON file, want %q", got, want)
+				}
+			}
+			if sqliteOut != "" {
+				if err := saveJobsToSQLite(jobGroups, sqliteOut, time.Now()); err != nil {
+					t.Fatalf("saveJobsToSQLite: %v", err)
+				}
+				db, err := sqlitedb.Open(sqliteOut)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer db.Close()
+				var jobs int
+				if err := db.QueryRow(`SELECT COUNT(*) FROM jobs`).Scan(&jobs); err != nil {
+					t.Fatal(err)
+				}
+				if want := countJobs(jobGroups); jobs != want {
+					t.Errorf("got %d jobs in the database, want %d", jobs, want)
+				}
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if name := entry.Name(); (name == "jobs.json" && !tt.json) || (strings.HasPrefix(name, "jobs.db") && !tt.sqlite) {
+					t.Errorf("%s was written, want no such output", name)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveJobsToSQLiteNormalizesCompanies(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.db")
+	runs := [][]JobCategoryGroup{
+		{{Category: "backend", Searches: []SearchGroup{{SearchTerm: "golang", Jobs: []*JobPosting{
+			{JobID: "1", Company: "Acme  Corp", Title: "Backend Developer", Description: "Go"},
+			{JobID: "2", Company: "Globex", Title: "Go Developer", Description: "Go"},
+		}}}}},
+		{{Category: "backend", Searches: []SearchGroup{{SearchTerm: "golang", Jobs: []*JobPosting{
+			{JobID: "3", Company: " Acme Corp\n", Title: "Senior Backend Developer", Description: "Go"},
+		}}}}},
+	}
+	for _, jobGroups := range runs {
+		if err := saveJobsToSQLite(jobGroups, sqliteFile, time.Now()); err != nil {
+			t.Fatalf("saveJobsToSQLite: %v", err)
+		}
+	}
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var acmeRows int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM companies WHERE company_name = 'Acme Corp'`).Scan(&acmeRows); err != nil {
+		t.Fatal(err)
+	}
+	if acmeRows != 1 {
+		t.Errorf("got %d company rows for Acme Corp, want 1", acmeRows)
+	}
+
+	counts, err := topHiringCompanies(db, 10)
+	if err != nil {
+		t.Fatalf("topHiringCompanies: %v", err)
+	}
+	want := []CompanyCount{{Company: "Acme Corp", Jobs: 2}, {Company: "Globex", Jobs: 1}}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("got top companies %+v, want %+v", counts, want)
+	}
+
+	counts, err = topHiringCompanies(db, 1)
+	if err != nil {
+		t.Fatalf("topHiringCompanies: %v", err)
+	}
+	if !reflect.DeepEqual(counts, want[:1]) {
+		t.Errorf("got top company %+v, want %+v", counts, want[:1])
+	}
+}
+
+func TestSearchTermCounts(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.db")
+	for i := 0; i < 2; i++ {
+		if err := saveJobsToSQLite(testJobGroups(), sqliteFile, time.Now()); err != nil {
+			t.Fatalf("saveJobsToSQLite: %v", err)
+		}
+	}
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	counts, err := searchTermCounts(db)
+	if err != nil {
+		t.Fatalf("searchTermCounts: %v", err)
+	}
+
+	want := []SearchTermCount{
+		{Category: "backend", SearchTerm: "golang", Jobs: 1},
+		{Category: "data", SearchTerm: "golang", Jobs: 2},
+		{Category: "data", SearchTerm: "spark", Jobs: 1},
+	}
+	if len(counts) != 2*len(want) {
+		t.Fatalf("got %d search term counts, want %d over two runs: %+v", len(counts), 2*len(want), counts)
+	}
+	for i, count := range counts {
+		if count.RunAt == "" {
+			t.Errorf("search term count %d has no run time", i)
+		}
+		count.RunAt = ""
+		if count != want[i%len(want)] {
+			t.Errorf("got search term count %d %+v, want %+v", i, count, want[i%len(want)])
+		}
+	}
+}
+
+func TestFilterJobCategories(t *testing.T) {
+	categories, err := filterJobCategories(getJobCategories(), []string{" security", ""})
+	if err != nil {
+		t.Fatalf("filterJobCategories: %v", err)
+	}
+	var searchTerms []string
+	for _, cat := range categories {
+		searchTerms = append(searchTerms, cat.SearchTerms...)
+	}
+	if want := []string{"security engineer", "security analyst"}; !slices.Equal(searchTerms, want) {
+		t.Errorf("--only=Security searches %q, want %q", searchTerms, want)
+	}
+
+	if _, err := filterJobCategories(getJobCategories(), []string{"Security", "Marketing"}); err == nil {
+		t.Error("filterJobCategories with an unknown category succeeded, want an error")
+	}
+}
+
+func TestPostedSince(t *testing.T) {
+	cutoff := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time { return &t }
+	tests := []struct {
+		name     string
+		postedAt *time.Time
+		want     bool
+	}{
+		{"recent", at(cutoff.Add(time.Hour)), true},
+		{"at the cutoff", at(cutoff), true},
+		{"old", at(cutoff.Add(-time.Hour)), false},
+		{"unknown date", nil, true},
+	}
+	for _, tt := range tests {
+		job := &JobPosting{JobID: "1", PostedAt: tt.postedAt}
+		if got := postedSince(job, cutoff); got != tt.want {
+			t.Errorf("%s: postedSince = %v, want %v", tt.name, got, tt.want)
+		}
+		if !postedSince(job, time.Time{}) {
+			t.Errorf("%s: postedSince without --since dropped the job", tt.name)
+		}
+	}
+}
+
+func TestInLocation(t *testing.T) {
+	pattern := regexp.MustCompile("(?i)argentina")
+	tests := []struct {
+		location string
+		want     bool
+	}{
+		{"Buenos Aires, Argentina", true},
+		{"Córdoba, ARGENTINA", true},
+		{"Montevideo, Uruguay", false},
+		{"São Paulo, Brazil", false},
+		{"", true},
+	}
+	for _, tt := range tests {
+		job := &JobPosting{JobID: "1", Location: tt.location}
+		if got := inLocation(job, pattern); got != tt.want {
+			t.Errorf("inLocation(%q) = %v, want %v", tt.location, got, tt.want)
+		}
+		if !inLocation(job, nil) {
+			t.Errorf("inLocation(%q) with no pattern dropped the job", tt.location)
+		}
+	}
+}
+
+func TestSeededRandReproducesRun(t *testing.T) {
+	// run returns the JSESSIONID and the start delays of the searches of a
+	// run with seed
+	run := func(seed int64) (string, []time.Duration) {
+		rng := seededRand(seed)
+		csrfToken := linkedin.NewCSRFToken(rng)
+		delays := make([]time.Duration, 5)
+		for i := range delays {
+			delays[i] = searchStartDelay(i, time.Second, rng)
+		}
+		return csrfToken, delays
+	}
+
+	token, delays := run(42)
+	againToken, againDelays := run(42)
+	if againToken != token || !slices.Equal(againDelays, delays) {
+		t.Errorf("seed 42 gave %q %v, then %q %v", token, delays, againToken, againDelays)
+	}
+
+	otherToken, otherDelays := run(43)
+	if otherToken == token || slices.Equal(otherDelays, delays) {
+		t.Errorf("seeds 42 and 43 both gave %q %v", token, delays)
+	}
+}
+
+func TestSaveJobsToSQLiteRefreshesFetchedJobs(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.db")
+	fetchedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := &JobPosting{JobID: "1", Company: "Acme", Title: "Backend Developer", Description: "Go",
+		Location: "Buenos Aires, Argentina", FetchedAt: &fetchedAt}
+	jobGroups := []JobCategoryGroup{{Category: "backend", Searches: []SearchGroup{{SearchTerm: "golang", Jobs: []*JobPosting{job}}}}}
+	if err := saveJobsToSQLite(jobGroups, sqliteFile, time.Now()); err != nil {
+		t.Fatalf("saveJobsToSQLite: %v", err)
+	}
+
+	refetchedAt := fetchedAt.Add(24 * time.Hour)
+	job.Description, job.Location, job.FetchedAt = "Go and Kubernetes", "Córdoba, Argentina", &refetchedAt
+	if err := saveJobsToSQLite(jobGroups, sqliteFile, time.Now()); err != nil {
+		t.Fatalf("saveJobsToSQLite: %v", err)
+	}
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var description, hash, location, storedFetchedAt string
+	err = db.QueryRow(`SELECT description, description_hash, location, fetched_at FROM jobs WHERE job_id = '1'`).
+		Scan(&description, &hash, &location, &storedFetchedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if description != job.Description {
+		t.Errorf("got description %q, want %q", description, job.Description)
+	}
+	if hash != sqlitedb.DescriptionHash(job.Description) {
+		t.Errorf("got description_hash %q, want the hash of %q", hash, job.Description)
+	}
+	if location != job.Location {
+		t.Errorf("got location %q, want %q", location, job.Location)
+	}
+	if storedFetchedAt != refetchedAt.Format(time.RFC3339) {
+		t.Errorf("got fetched_at %q, want %q", storedFetchedAt, refetchedAt.Format(time.RFC3339))
+	}
+}
+
+func TestSaveJobsToSQLiteKeepsFirstSeen(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.db")
+	firstRun := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	secondRun := firstRun.Add(24 * time.Hour)
+	for _, runAt := range []time.Time{firstRun, secondRun} {
+		if err := saveJobsToSQLite(testJobGroups(), sqliteFile, runAt); err != nil {
+			t.Fatalf("saveJobsToSQLite: %v", err)
+		}
+	}
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT s.search_term, sj.job_id, sj.first_seen, sj.last_seen
+		FROM searches_jobs sj JOIN searches s ON s.search_id = sj.search_id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	pairs := 0
+	for rows.Next() {
+		var searchTerm, jobID, firstSeen, lastSeen string
+		if err := rows.Scan(&searchTerm, &jobID, &firstSeen, &lastSeen); err != nil {
+			t.Fatal(err)
+		}
+		pairs++
+		if want := firstRun.Format(time.RFC3339); firstSeen != want {
+			t.Errorf("got first_seen %q for job %s of %q, want %q", firstSeen, jobID, searchTerm, want)
+		}
+		if want := secondRun.Format(time.RFC3339); lastSeen != want {
+			t.Errorf("got last_seen %q for job %s of %q, want %q", lastSeen, jobID, searchTerm, want)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if pairs != 3 {
+		t.Errorf("got %d search-job pairs, want 3", pairs)
+	}
+}
+
+// fakeLinkedIn serves the listings of searches, mapping keywords or company
+// search terms to job IDs, and a posting for every job listed.
+func fakeLinkedIn(t *testing.T, searches map[string][]string) *linkedin.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if jid, ok := strings.CutPrefix(r.URL.Path, "/voyager/api/jobs/jobPostings/"); ok {
+			fmt.Fprintf(w, `{
+				"companyDetails": {"com.linkedin.voyager.deco.jobs.web.shared.WebJobPostingCompany": {"companyResolutionResult": {"name": "Company %[1]s"}}},
+				"description": {"text": "<p>Description of the job number %[1]s, looking for a developer with experience.</p>"},
+				"title": "Developer %[1]s",
+				"formattedLocation": "Buenos Aires, Argentina",
+				"listedAt": 1735787045000
+			}`, jid)
+			return
+		}
+		if r.URL.Path != "/voyager/api/voyagerJobsDashJobCards" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		var ids []string
+		for searchTerm, searchIDs := range searches {
+			filter := fmt.Sprintf("keywords:%q", searchTerm)
+			if id, ok := strings.CutPrefix(searchTerm, companySearchPrefix); ok {
+				filter = "company:List(" + id + ")"
+			}
+			if strings.Contains(r.URL.Query().Get("query"), filter) {
+				ids = searchIDs
+			}
+		}
+		if r.URL.Query().Get("start") != "0" {
+			ids = nil
+		}
+		urns := make([]string, len(ids))
+		for i, id := range ids {
+			urns[i] = fmt.Sprintf(`"urn:li:fsd_jobPostingCard:(%s,JOB_DETAILS)"`, id)
+		}
+		fmt.Fprintf(w, `{
+			"metadata": {"jobCardPrefetchQueries": [{"prefetchJobPostingCardUrns": [%s]}]},
+			"paging": {"total": %d, "start": %s, "count": %d}
+		}`, strings.Join(urns, ","), len(ids), r.URL.Query().Get("start"), len(ids))
+	}))
+	t.Cleanup(server.Close)
+
+	client := linkedin.NewClient(server.Client(), rate.NewLimiter(rate.Inf, 1), linkedin.NewTokenPool([]string{"test-token"}))
+	client.BaseURL = server.URL
+	return client
+}
+
+// postingCounter wraps the HTTP client of a fakeLinkedIn, counting the
+// posting requests of every job and answering 503 to the first failures[jid]
+// of them.
+type postingCounter struct {
+	linkedin.Doer
+
+	mu       sync.Mutex
+	requests map[JobID]int
+	failures map[JobID]int
+}
+
+// countPostings makes client send its requests through a postingCounter.
+func countPostings(client *linkedin.Client, failures map[JobID]int) *postingCounter {
+	counter := &postingCounter{Doer: client.HTTPClient, requests: make(map[JobID]int), failures: failures}
+	client.HTTPClient = counter
+	return counter
+}
+
+func (c *postingCounter) Do(req *http.Request) (*http.Response, error) {
+	jid, ok := strings.CutPrefix(req.URL.Path, "/voyager/api/jobs/jobPostings/")
+	if !ok {
+		return c.Doer.Do(req)
+	}
+
+	c.mu.Lock()
+	c.requests[jid]++
+	fail := c.requests[jid] <= c.failures[jid]
+	c.mu.Unlock()
+
+	if fail {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Status:     "503 Service Unavailable",
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+	return c.Doer.Do(req)
+}
+
+// jobIDs returns the sorted IDs of the jobs of every search of jobGroups.
+func jobIDs(jobGroups []JobCategoryGroup) []JobID {
+	var ids []JobID
+	for _, jobGroup := range jobGroups {
+		for _, searchGroup := range jobGroup.Searches {
+			for _, job := range searchGroup.Jobs {
+				ids = append(ids, job.JobID)
+			}
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// persistedJobs returns the jobs stored in sqliteFile with their categories
+// and searches, one line per job and relationship, leaving out the times of
+// the run.
+func persistedJobs(t *testing.T, sqliteFile string) []string {
+	t.Helper()
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT 'job ' || job_id || ' ' || company || ' ' || title || ' ' || location || ' ' || posted_at || ' ' || description FROM jobs
+		UNION ALL
+		SELECT 'category ' || c.category_name || ' ' || jc.job_id FROM jobs_categories jc JOIN categories c ON c.category_id = jc.category_id
+		UNION ALL
+		SELECT 'search ' || s.search_term || ' ' || sj.job_id FROM searches_jobs sj JOIN searches s ON s.search_id = sj.search_id
+		ORDER BY 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestScrapeJobsSequentialMatchesConcurrent(t *testing.T) {
+	categories := []JobCategory{
+		{Category: "backend", SearchTerms: []string{"golang", "java"}},
+		{Category: "data", SearchTerms: []string{"spark", "golang"}},
+	}
+	client := fakeLinkedIn(t, map[string][]string{
+		"golang": {"1", "2", "3"},
+		"java":   {"3", "4"},
+		"spark":  {"5", "1"},
+	})
+
+	var persisted [][]string
+	for _, sequential := range []bool{false, true} {
+		dir := t.TempDir()
+		progress, err := openCheckpoint(filepath.Join(dir, "jobs.db.progress"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer progress.Close()
+
+		jobGroups, _, err := scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+			GeoID:        linkedin.GeoIDArgentina,
+			RoleFamilies: linkedin.DefaultRoleFamilies(),
+			Sequential:   sequential,
+			Progress:     progress,
+		})
+		if err != nil {
+			t.Fatalf("scrapeJobs (sequential %v): %v", sequential, err)
+		}
+
+		sqliteFile := filepath.Join(dir, "jobs.db")
+		if err := saveJobsToSQLite(jobGroups, sqliteFile, time.Now()); err != nil {
+			t.Fatalf("saveJobsToSQLite: %v", err)
+		}
+		persisted = append(persisted, persistedJobs(t, sqliteFile))
+	}
+
+	if want := 5 + 7 + 8; len(persisted[0]) != want {
+		t.Errorf("concurrent run persisted %d rows, want %d: %q", len(persisted[0]), want, persisted[0])
+	}
+	if !reflect.DeepEqual(persisted[1], persisted[0]) {
+		t.Errorf("sequential run persisted\n%q\nconcurrent run persisted\n%q", persisted[1], persisted[0])
+	}
+}
+
+// pagedListings answers the listings requests of a fakeLinkedIn with total
+// jobs, pageSize of them per page, counting the pages requested.
+type pagedListings struct {
+	linkedin.Doer
+	total, pageSize int
+	pages           atomic.Int32
+}
+
+func (d *pagedListings) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Path != "/voyager/api/voyagerJobsDashJobCards" {
+		return d.Doer.Do(req)
+	}
+	d.pages.Add(1)
+
+	start, _ := strconv.Atoi(req.URL.Query().Get("start"))
+	var urns []string
+	for id := start + 1; id <= min(start+d.pageSize, d.total); id++ {
+		urns = append(urns, fmt.Sprintf(`"urn:li:fsd_jobPostingCard:(%d,JOB_DETAILS)"`, id))
+	}
+	body := fmt.Sprintf(`{
+		"metadata": {"jobCardPrefetchQueries": [{"prefetchJobPostingCardUrns": [%s]}]},
+		"paging": {"total": %d, "start": %d, "count": %d}
+	}`, strings.Join(urns, ","), d.total, start, len(urns))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestScrapeJobsMaxPerSearch(t *testing.T) {
+	progress, err := openCheckpoint(filepath.Join(t.TempDir(), "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	client := fakeLinkedIn(t, nil)
+	listings := &pagedListings{Doer: client.HTTPClient, total: 50, pageSize: 25}
+	client.HTTPClient = listings
+	postings := countPostings(client, nil)
+
+	// scrapeJobs only returns once the listings producer closed its channel,
+	// so a producer that doesn't stop fails the test by timing out
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang"}}}
+	jobGroups, _, err := scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		MaxPerSearch: 10,
+		Progress:     progress,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	if ids := jobIDs(jobGroups); len(ids) != 10 {
+		t.Errorf("got %d jobs, want 10: %q", len(ids), ids)
+	}
+	if n := len(postings.requests); n != 10 {
+		t.Errorf("fetched %d postings, want 10", n)
+	}
+	if n := listings.pages.Load(); n != 1 {
+		t.Errorf("requested %d listing pages, want the producer to stop after the first", n)
+	}
+}
+
+func TestScrapeJobsMinDescChars(t *testing.T) {
+	progress, err := openCheckpoint(filepath.Join(t.TempDir(), "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	// The descriptions of fakeLinkedIn are 72 characters plus the job ID, so
+	// job 1 is one character short of the threshold and job 12 right at it.
+	client := fakeLinkedIn(t, map[string][]string{"golang": {"1", "12", "123"}})
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang"}}}
+	jobGroups, stats, err := scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		MinDescChars: 74,
+		Progress:     progress,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	if ids, want := jobIDs(jobGroups), []JobID{"12", "123"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got jobs %q, want %q", ids, want)
+	}
+	if stats.ShortDescriptions != 1 {
+		t.Errorf("got %d short descriptions, want 1", stats.ShortDescriptions)
+	}
+}
+
+func TestLogSQLiteFailure(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.db")
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	logSQLiteFailure(logger, testJobGroups(), sqliteFile, now, "could not save jobs to SQLite: %v", errors.New("disk I/O error"))
+
+	fallback := filepath.Join(filepath.Dir(sqliteFile), "jobs-fallback-2025-01-02T03-04-05Z.json")
+	want := "could not save jobs to SQLite: disk I/O error. The jobs were saved to " + fallback + " instead so they are not lost\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got log %q, want %q", got, want)
+	}
+}
+
+// listingStarts records when the first listings request of every search of a
+// fakeLinkedIn is sent.
+type listingStarts struct {
+	linkedin.Doer
+
+	mu     sync.Mutex
+	starts map[string]time.Time
+}
+
+func (d *listingStarts) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/voyager/api/voyagerJobsDashJobCards" {
+		query := req.URL.Query().Get("query")
+		d.mu.Lock()
+		if _, ok := d.starts[query]; !ok {
+			d.starts[query] = time.Now()
+		}
+		d.mu.Unlock()
+	}
+	return d.Doer.Do(req)
+}
+
+func TestScrapeJobsStaggersSearches(t *testing.T) {
+	progress, err := openCheckpoint(filepath.Join(t.TempDir(), "jobs.db.progress"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+
+	client := fakeLinkedIn(t, map[string][]string{"golang": {"1"}, "python": {"2"}, "java": {"3"}})
+	recorder := &listingStarts{Doer: client.HTTPClient, starts: make(map[string]time.Time)}
+	client.HTTPClient = recorder
+
+	const stagger = 50 * time.Millisecond
+	categories := []JobCategory{{Category: "backend", SearchTerms: []string{"golang", "python", "java"}}}
+	_, _, err = scrapeJobs(context.Background(), client, rate.NewLimiter(rate.Inf, 1), categories, scrapeConfig{
+		GeoID:        linkedin.GeoIDArgentina,
+		RoleFamilies: linkedin.DefaultRoleFamilies(),
+		Stagger:      stagger,
+		Rng:          seededRand(1),
+		Progress:     progress,
+	})
+	if err != nil {
+		t.Fatalf("scrapeJobs: %v", err)
+	}
+
+	var starts []time.Time
+	for _, start := range recorder.starts {
+		starts = append(starts, start)
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	if len(starts) != 3 {
+		t.Fatalf("got %d searches, want 3", len(starts))
+	}
+
+	// The search launched i-th starts i staggers in, give or take half of one
+	for i := 1; i < len(starts); i++ {
+		if got, want := starts[i].Sub(starts[0]), time.Duration(2*i-1)*stagger/2; got < want {
+			t.Errorf("search %d started %v after the first, want at least %v", i, got, want)
+		}
+	}
+}
+
+func TestSearchStartDelay(t *testing.T) {
+	rng := seededRand(1)
+	const stagger = time.Second
+	for i := range 20 {
+		delay := searchStartDelay(i, stagger, rng)
+		if i == 0 && delay != 0 {
+			t.Errorf("first search waits %v, want 0", delay)
+		}
+		if earliest, latest := time.Duration(i)*stagger-stagger/2, time.Duration(i)*stagger+stagger/2; i > 0 && (delay < earliest || delay >= latest) {
+			t.Errorf("search %d waits %v, want within [%v, %v)", i, delay, earliest, latest)
+		}
+	}
+	if delay := searchStartDelay(3, 0, rng); delay != 0 {
+		t.Errorf("got %v without a stagger, want 0", delay)
+	}
+}
+
+func TestSaveSQLiteFallback(t *testing.T) {
+	// A regular file where the database directory should be makes both the
+	// database and the fallback next to it unwritable, even as root.
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sqliteFile := filepath.Join(blocker, "jobs.db")
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	jobGroups := testJobGroups()
+	if err := saveJobsToSQLite(jobGroups, sqliteFile, time.Now()); err == nil {
+		t.Fatal("saveJobsToSQLite succeeded on an unwritable path, want an error")
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	path, err := saveSQLiteFallback(jobGroups, sqliteFile, now)
+	if err != nil {
+		t.Fatalf("saveSQLiteFallback: %v", err)
+	}
+	if want := filepath.Join(tmpDir, "jobs-fallback-2025-01-02T03-04-05Z.json"); path != want {
+		t.Errorf("got fallback %s, want %s", path, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []JobCategoryGroup
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("could not decode the fallback: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, jobGroups) {
+		t.Errorf("fallback decodes to %+v, want %+v", decoded, jobGroups)
+	}
+}
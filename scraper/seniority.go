@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// unknownSeniority buckets the analyzed jobs with no seniority extracted.
+const unknownSeniority = "unknown"
+
+// seniorityLevels are the seniorities the analyzer extracts, always listed in
+// the report, in this order, even when no job has them.
+var seniorityLevels = []string{"Junior", "Semisenior", "Senior", "Lead"}
+
+// SeniorityCount is the number of analyzed jobs of a category with a
+// seniority, and their percentage of the category's analyzed jobs.
+type SeniorityCount struct {
+	Seniority string  `json:"seniority"`
+	Jobs      int     `json:"jobs"`
+	Percent   float64 `json:"percent"`
+}
+
+// CategorySeniority is the seniority breakdown of the analyzed jobs of a
+// category.
+type CategorySeniority struct {
+	Category    string           `json:"category"`
+	Jobs        int              `json:"jobs"`
+	Seniorities []SeniorityCount `json:"seniorities"`
+}
+
+// seniorityDistribution returns the seniority breakdown per category of the
+// jobs with an analysis in the job_analyses table of db, sorted by category.
+func seniorityDistribution(db *sql.DB) ([]CategorySeniority, error) {
+	rows, err := db.Query(`
+		SELECT c.category_name,
+			COALESCE(NULLIF(TRIM(json_extract(a.analysis, '$.seniority')), ''), ?) AS seniority,
+			COUNT(*) AS jobs
+		FROM job_analyses a
+		JOIN jobs_categories jc ON jc.job_id = a.job_id
+		JOIN categories c ON c.category_id = jc.category_id
+		GROUP BY c.category_name, seniority
+		ORDER BY c.category_name, seniority`, unknownSeniority)
+	if err != nil {
+		return nil, fmt.Errorf("could not query seniority distribution: %v", err)
+	}
+	defer rows.Close()
+
+	var report []CategorySeniority
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var category, seniority string
+		var jobs int
+		if err := rows.Scan(&category, &seniority, &jobs); err != nil {
+			return nil, fmt.Errorf("could not read seniority distribution: %v", err)
+		}
+
+		if counts[category] == nil {
+			counts[category] = make(map[string]int)
+			report = append(report, CategorySeniority{Category: category})
+		}
+		counts[category][seniority] += jobs
+		report[len(report)-1].Jobs += jobs
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read seniority distribution: %v", err)
+	}
+
+	for i := range report {
+		report[i].Seniorities = seniorityCounts(counts[report[i].Category], report[i].Jobs)
+	}
+
+	return report, nil
+}
+
+// seniorityCounts lists counts with the known seniority levels first, then any
+// other value the analyzer returned in alphabetical order, and the unknown
+// bucket last.
+func seniorityCounts(counts map[string]int, total int) []SeniorityCount {
+	order := append([]string{}, seniorityLevels...)
+	known := map[string]bool{unknownSeniority: true}
+	for _, level := range seniorityLevels {
+		known[level] = true
+	}
+
+	var others []string
+	for seniority := range counts {
+		if !known[seniority] {
+			others = append(others, seniority)
+		}
+	}
+	sort.Strings(others)
+	order = append(append(order, others...), unknownSeniority)
+
+	seniorities := make([]SeniorityCount, 0, len(order))
+	for _, seniority := range order {
+		count := SeniorityCount{Seniority: seniority, Jobs: counts[seniority]}
+		if total > 0 {
+			count.Percent = float64(count.Jobs) * 100 / float64(total)
+		}
+		seniorities = append(seniorities, count)
+	}
+	return seniorities
+}
+
+// writeSeniorityReport writes report to w in format, either json or table.
+func writeSeniorityReport(w io.Writer, report []CategorySeniority, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if report == nil {
+			report = []CategorySeniority{}
+		}
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("could not encode seniority report: %v", err)
+		}
+		return nil
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CATEGORY\tSENIORITY\tJOBS\tPERCENT")
+		for _, category := range report {
+			for _, count := range category.Seniorities {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%.1f%%\n", category.Category, count.Seniority, count.Jobs, count.Percent)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%.1f%%\n", category.Category, "total", category.Jobs, 100.0)
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("could not write seniority report: %v", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown report format '%s': use json or table", format)
+	}
+}
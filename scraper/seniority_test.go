@@ -0,0 +1,72 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"linkedinScraper/sqlitedb"
+)
+
+// seedAnalyzedDB saves testJobGroups to a new SQLite store and adds the
+// analysis of every job in analyses, returning the store's path.
+func seedAnalyzedDB(t *testing.T, analyses map[JobID]string) string {
+	t.Helper()
+
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.db")
+	if err := saveJobsToSQLite(testJobGroups(), sqliteFile, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("saveJobsToSQLite: %v", err)
+	}
+
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for jid, analysis := range analyses {
+		_, err := db.Exec(`INSERT INTO job_analyses (job_id, model, analysis, analyzed_at) VALUES (?, 'test-model', ?, '2025-01-04T00:00:00Z')`, jid, analysis)
+		if err != nil {
+			t.Fatalf("could not seed analysis of job %s: %v", jid, err)
+		}
+	}
+	return sqliteFile
+}
+
+func TestSeniorityDistribution(t *testing.T) {
+	// Job 1 is listed in both categories, job 2 only in data.
+	sqliteFile := seedAnalyzedDB(t, map[JobID]string{
+		"1": `{"seniority": "Senior"}`,
+		"2": `{"seniority": " "}`,
+	})
+	db, err := sqlitedb.Open(sqliteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	report, err := seniorityDistribution(db)
+	if err != nil {
+		t.Fatalf("seniorityDistribution: %v", err)
+	}
+
+	want := []CategorySeniority{
+		{Category: "backend", Jobs: 1, Seniorities: []SeniorityCount{
+			{Seniority: "Junior"},
+			{Seniority: "Semisenior"},
+			{Seniority: "Senior", Jobs: 1, Percent: 100},
+			{Seniority: "Lead"},
+			{Seniority: unknownSeniority},
+		}},
+		{Category: "data", Jobs: 2, Seniorities: []SeniorityCount{
+			{Seniority: "Junior"},
+			{Seniority: "Semisenior"},
+			{Seniority: "Senior", Jobs: 1, Percent: 50},
+			{Seniority: "Lead"},
+			{Seniority: unknownSeniority, Jobs: 1, Percent: 50},
+		}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got %+v, want %+v", report, want)
+	}
+}
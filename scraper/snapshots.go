@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// snapshotTimeFormat is RFC 3339 in UTC with the colons replaced, since they
+// are not valid in file names on every platform.
+const snapshotTimeFormat = "2006-01-02T15-04-05Z"
+
+// Snapshot is an entry of the snapshot index.
+type Snapshot struct {
+	File    string    `json:"file"`
+	TakenAt time.Time `json:"taken_at"`
+	Jobs    int       `json:"jobs"`
+}
+
+// snapshotPath returns the file a run taken at t writes to instead of
+// outputFile, e.g. jobs.json becomes jobs-2025-01-02T15-04-05Z.json.
+func snapshotPath(outputFile string, t time.Time) string {
+	ext := filepath.Ext(outputFile)
+	return strings.TrimSuffix(outputFile, ext) + "-" + t.UTC().Format(snapshotTimeFormat) + ext
+}
+
+// snapshotIndexPath returns the index listing the snapshots of outputFile.
+func snapshotIndexPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".index.json"
+}
+
+// saveSnapshot writes jobGroups to a new snapshot of outputFile taken at t and
+// adds it to the snapshot index, returning the snapshot's path.
+func saveSnapshot(jobGroups []JobCategoryGroup, outputFile string, t time.Time, dedup, compact bool) (string, error) {
+	path := snapshotPath(outputFile, t)
+	if err := saveJobsToFile(jobGroups, path, dedup, compact); err != nil {
+		return "", err
+	}
+
+	indexPath := snapshotIndexPath(outputFile)
+	snapshots, err := readSnapshotIndex(indexPath)
+	if err != nil {
+		return "", err
+	}
+
+	snapshots = append(snapshots, Snapshot{
+		File:    filepath.Base(path),
+		TakenAt: t.UTC().Truncate(time.Second),
+		Jobs:    countJobs(jobGroups),
+	})
+
+	err = writeFileAtomic(indexPath, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(snapshots); err != nil {
+			return fmt.Errorf("could not encode snapshot index: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// readSnapshotIndex returns the snapshots listed at path, none if it does not
+// exist yet.
+func readSnapshotIndex(path string) ([]Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read snapshot index '%s': %v", path, err)
+	}
+
+	var snapshots []Snapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("could not decode snapshot index '%s': %v", path, err)
+	}
+	return snapshots, nil
+}
+
+// countJobs returns the number of distinct jobs in jobGroups.
+func countJobs(jobGroups []JobCategoryGroup) int {
+	seen := make(map[JobID]bool)
+	for _, jobGroup := range jobGroups {
+		for _, searchGroup := range jobGroup.Searches {
+			for _, job := range searchGroup.Jobs {
+				seen[job.JobID] = true
+			}
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveSnapshotKeepsEveryRun(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "jobs.json")
+	first := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	runs := []time.Time{first, first.Add(time.Hour)}
+
+	var paths []string
+	for _, runAt := range runs {
+		path, err := saveSnapshot(testJobGroups(), outputFile, runAt, false, false)
+		if err != nil {
+			t.Fatalf("saveSnapshot: %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("snapshot %s was not written: %v", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	if paths[0] == paths[1] {
+		t.Fatalf("both runs wrote to %s, want distinct snapshots", paths[0])
+	}
+	if want := filepath.Join(filepath.Dir(outputFile), "jobs-2025-01-02T03-04-05Z.json"); paths[0] != want {
+		t.Errorf("got snapshot %s, want %s", paths[0], want)
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		t.Errorf("%s was written, want only snapshots", outputFile)
+	}
+
+	snapshots, err := readSnapshotIndex(snapshotIndexPath(outputFile))
+	if err != nil {
+		t.Fatalf("readSnapshotIndex: %v", err)
+	}
+	if len(snapshots) != len(runs) {
+		t.Fatalf("got %d snapshots in the index, want %d", len(snapshots), len(runs))
+	}
+	for i, snapshot := range snapshots {
+		if snapshot.File != filepath.Base(paths[i]) || !snapshot.TakenAt.Equal(runs[i]) || snapshot.Jobs != 2 {
+			t.Errorf("got snapshot %+v, want %s taken at %v with 2 jobs", snapshot, filepath.Base(paths[i]), runs[i])
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package sqlitedb
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"linkedinScraper/linkedin"
+)
+
+// SaveJob stores job in the jobs table within tx, along with its company, job
+// functions and LinkedIn skills. A job stored before is only refreshed when
+// job was fetched after it, but it always gets the current role family and
+// dedup group.
+func SaveJob(tx *sql.Tx, job *linkedin.JobPosting) error {
+	var salaryMin, salaryMax *float64
+	var salaryCurrency, salaryPeriod sql.NullString
+	if job.Salary != nil {
+		salaryMin, salaryMax = job.Salary.Min, job.Salary.Max
+		salaryCurrency, salaryPeriod = nullString(job.Salary.Currency), nullString(job.Salary.Period)
+	}
+
+	var extra sql.NullString
+	if len(job.Extra) > 0 {
+		data, err := json.Marshal(job.Extra)
+		if err != nil {
+			return fmt.Errorf("could not encode extra fields of job '%s': %v", job.JobID, err)
+		}
+		extra = nullString(string(data))
+	}
+
+	var postedAt, fetchedAt sql.NullString
+	if job.PostedAt != nil {
+		postedAt = nullString(job.PostedAt.Format(time.RFC3339))
+	}
+	if job.FetchedAt != nil {
+		fetchedAt = nullString(job.FetchedAt.UTC().Format(time.RFC3339))
+	}
+
+	// Insert or get company
+	var companyID sql.NullInt64
+	if companyName := NormalizeCompanyName(job.Company); companyName != "" {
+		err := tx.QueryRow(`
+			INSERT INTO companies (company_name) VALUES (?)
+			ON CONFLICT(company_name) DO UPDATE SET company_name=company_name
+			RETURNING company_id`, companyName).Scan(&companyID)
+		if err != nil {
+			return fmt.Errorf("could not insert/get company '%s': %v", companyName, err)
+		}
+	}
+
+	// Insert job if not exists
+	_, err := tx.Exec(`
+		INSERT OR IGNORE INTO jobs (job_id, company, description, title, employment_type,
+			salary_min, salary_max, salary_currency, salary_period, posted_at, company_id, language, extra, dedup_group,
+			fetched_at, description_hash, normalized_title, role_family, location, workplace_type)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.JobID, job.Company, job.Description, job.Title, nullString(job.EmploymentType),
+		salaryMin, salaryMax, salaryCurrency, salaryPeriod, postedAt, companyID, nullString(job.Language), extra,
+		nullString(job.DedupGroup), fetchedAt, DescriptionHash(job.Description),
+		nullString(job.NormalizedTitle), nullString(job.RoleFamily), nullString(job.Location),
+		nullString(job.WorkplaceType))
+	if err != nil {
+		return fmt.Errorf("could not insert job '%s': %v", job.JobID, err)
+	}
+
+	// Refresh the postings stored by earlier runs with the ones
+	// fetched again, so they are cached for another --cache-ttl
+	if fetchedAt.Valid {
+		_, err = tx.Exec(`
+			UPDATE jobs SET company = ?, description = ?, title = ?, employment_type = ?,
+				salary_min = ?, salary_max = ?, salary_currency = ?, salary_period = ?, posted_at = ?,
+				language = ?, extra = ?, fetched_at = ?, description_hash = ?, location = ?,
+				workplace_type = ?
+			WHERE job_id = ? AND (fetched_at IS NULL OR fetched_at < ?)`,
+			job.Company, job.Description, job.Title, nullString(job.EmploymentType),
+			salaryMin, salaryMax, salaryCurrency, salaryPeriod, postedAt,
+			nullString(job.Language), extra, fetchedAt, DescriptionHash(job.Description), nullString(job.Location),
+			nullString(job.WorkplaceType), job.JobID, fetchedAt)
+		if err != nil {
+			return fmt.Errorf("could not refresh job '%s': %v", job.JobID, err)
+		}
+	}
+
+	// Classify jobs stored by earlier runs with the current role
+	// families
+	_, err = tx.Exec(`UPDATE jobs SET normalized_title = ?, role_family = ? WHERE job_id = ?`,
+		nullString(job.NormalizedTitle), nullString(job.RoleFamily), job.JobID)
+	if err != nil {
+		return fmt.Errorf("could not set role family of job '%s': %v", job.JobID, err)
+	}
+
+	// Group jobs stored by earlier runs with their reposts
+	if job.DedupGroup != "" {
+		_, err = tx.Exec(`UPDATE jobs SET dedup_group = ? WHERE job_id = ?`, job.DedupGroup, job.JobID)
+		if err != nil {
+			return fmt.Errorf("could not set dedup group of job '%s': %v", job.JobID, err)
+		}
+	}
+
+	// Link jobs stored before companies were normalized
+	_, err = tx.Exec(`
+		UPDATE jobs SET company_id = ? WHERE job_id = ? AND company_id IS NULL`,
+		companyID, job.JobID)
+	if err != nil {
+		return fmt.Errorf("could not set company of job '%s': %v", job.JobID, err)
+	}
+
+	for _, jobFunction := range job.JobFunctions {
+		// Insert or get job function
+		var jobFunctionID int64
+		err = tx.QueryRow(`
+			INSERT INTO job_functions (job_function_name) VALUES (?)
+			ON CONFLICT(job_function_name) DO UPDATE SET job_function_name=job_function_name
+			RETURNING job_function_id`, jobFunction).Scan(&jobFunctionID)
+		if err != nil {
+			return fmt.Errorf("could not insert/get job function '%s': %v", jobFunction, err)
+		}
+
+		// Insert job-job function relationship
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO jobs_job_functions (job_id, job_function_id)
+			VALUES (?, ?)`, job.JobID, jobFunctionID)
+		if err != nil {
+			return fmt.Errorf("could not insert job-job function relationship for job '%s' and job function '%d': %v", job.JobID, jobFunctionID, err)
+		}
+	}
+
+	for _, skill := range job.LinkedInSkills {
+		// Insert or get LinkedIn skill
+		var skillID int64
+		err = tx.QueryRow(`
+			INSERT INTO linkedin_skills (skill_name) VALUES (?)
+			ON CONFLICT(skill_name) DO UPDATE SET skill_name=skill_name
+			RETURNING skill_id`, skill).Scan(&skillID)
+		if err != nil {
+			return fmt.Errorf("could not insert/get LinkedIn skill '%s': %v", skill, err)
+		}
+
+		// Insert job-LinkedIn skill relationship
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO jobs_linkedin_skills (job_id, skill_id)
+			VALUES (?, ?)`, job.JobID, skillID)
+		if err != nil {
+			return fmt.Errorf("could not insert job-LinkedIn skill relationship for job '%s' and skill '%d': %v", job.JobID, skillID, err)
+		}
+	}
+
+	return nil
+}
+
+// DescriptionHash returns the hash stored in jobs.description_hash.
+func DescriptionHash(description string) string {
+	sum := sha256.Sum256([]byte(description))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeCompanyName trims and collapses the whitespace of a company name so
+// the same employer is stored once in the companies table.
+func NormalizeCompanyName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// nullString stores empty strings as NULL, for columns LinkedIn does not
+// always provide.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,344 @@
+package sqlitedb
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration upgrades the SQLite schema to version.
+type migration struct {
+	version int
+	up      func(tx *sql.Tx) error
+}
+
+// migrations are applied in order to bring a database to the latest schema.
+// Append new ones at the end; never change a migration once released, as
+// databases that already applied it won't run it again.
+var migrations = []migration{
+	{version: 1, up: migrateInitialSchema},
+	{version: 2, up: migrateLinkedInSkills},
+	{version: 3, up: migrateJobExtra},
+	{version: 4, up: migrateJobDedupGroup},
+	{version: 5, up: migrateJobFetchedAt},
+	{version: 6, up: migrateKeepFirstSeen},
+	{version: 7, up: migrateJobDescriptionHash},
+	{version: 8, up: migrateJobAnalyses},
+	{version: 9, up: migrateJobRoleFamily},
+	{version: 10, up: migrateJobLocation},
+	{version: 11, up: migrateFetchMeta},
+	{version: 12, up: migrateJobWorkplaceType},
+	{version: 13, up: migrateAnalysisMinYears},
+}
+
+// execQuerier is satisfied by both *sql.DB and *sql.Tx.
+type execQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// migrate applies the migrations newer than the version recorded in the
+// schema_version table, each in its own transaction.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("could not create schema_version table: %v", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&current); err != nil {
+		return fmt.Errorf("could not read schema version: %v", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("could not migrate database to version %d: %v", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM schema_version`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES (?)`, m.version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// migrateInitialSchema creates the schema as it was when versioning was
+// introduced. Databases created before then already have some of it, so every
+// step is a no-op when the table, index or column exists.
+func migrateInitialSchema(tx *sql.Tx) error {
+	createTables := []string{
+		`CREATE TABLE IF NOT EXISTS companies (
+			company_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			company_name TEXT NOT NULL UNIQUE
+		)`,
+		`CREATE TABLE IF NOT EXISTS jobs (
+			job_id TEXT PRIMARY KEY,
+			company TEXT NOT NULL,
+			description TEXT NOT NULL,
+			title TEXT NOT NULL,
+			employment_type TEXT,
+			salary_min REAL,
+			salary_max REAL,
+			salary_currency TEXT,
+			salary_period TEXT,
+			posted_at TEXT,
+			company_id INTEGER REFERENCES companies(company_id),
+			language TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS categories (
+			category_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			category_name TEXT NOT NULL UNIQUE
+		)`,
+		`CREATE TABLE IF NOT EXISTS jobs_categories (
+			job_id TEXT,
+			category_id INTEGER,
+			PRIMARY KEY (job_id, category_id),
+			FOREIGN KEY (job_id) REFERENCES jobs(job_id),
+			FOREIGN KEY (category_id) REFERENCES categories(category_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS searches (
+			search_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			search_term TEXT NOT NULL UNIQUE
+		)`,
+		`CREATE TABLE IF NOT EXISTS searches_jobs (
+			search_id INTEGER,
+			job_id TEXT,
+			first_seen TEXT NOT NULL,
+			last_seen TEXT NOT NULL,
+			PRIMARY KEY (search_id, job_id),
+			FOREIGN KEY (search_id) REFERENCES searches(search_id),
+			FOREIGN KEY (job_id) REFERENCES jobs(job_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS job_functions (
+			job_function_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_function_name TEXT NOT NULL UNIQUE
+		)`,
+		`CREATE TABLE IF NOT EXISTS jobs_job_functions (
+			job_id TEXT,
+			job_function_id INTEGER,
+			PRIMARY KEY (job_id, job_function_id),
+			FOREIGN KEY (job_id) REFERENCES jobs(job_id),
+			FOREIGN KEY (job_function_id) REFERENCES job_functions(job_function_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS scrape_runs (
+			run_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS search_results (
+			run_id INTEGER,
+			category TEXT NOT NULL,
+			search_term TEXT NOT NULL,
+			job_count INTEGER NOT NULL,
+			PRIMARY KEY (run_id, category, search_term),
+			FOREIGN KEY (run_id) REFERENCES scrape_runs(run_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_searches_jobs_last_seen ON searches_jobs(last_seen)`,
+		`CREATE INDEX IF NOT EXISTS idx_searches_jobs_job_id ON searches_jobs(job_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_jobs_categories_category_id ON jobs_categories(category_id)`,
+	}
+
+	for _, query := range createTables {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("could not create table or index: %v", err)
+		}
+	}
+
+	// Add columns introduced after the tables were first created
+	newColumns := []struct{ table, column, decl string }{
+		{"jobs", "employment_type", "TEXT"},
+		{"jobs", "salary_min", "REAL"},
+		{"jobs", "salary_max", "REAL"},
+		{"jobs", "salary_currency", "TEXT"},
+		{"jobs", "salary_period", "TEXT"},
+		{"jobs", "posted_at", "TEXT"},
+		{"jobs", "company_id", "INTEGER REFERENCES companies(company_id)"},
+		{"jobs", "language", "TEXT"},
+	}
+	for _, c := range newColumns {
+		if err := addColumnIfMissing(tx, c.table, c.column, c.decl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateLinkedInSkills adds the skills LinkedIn tags postings with.
+func migrateLinkedInSkills(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE linkedin_skills (
+			skill_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			skill_name TEXT NOT NULL UNIQUE
+		)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE jobs_linkedin_skills (
+			job_id TEXT,
+			skill_id INTEGER,
+			PRIMARY KEY (job_id, skill_id),
+			FOREIGN KEY (job_id) REFERENCES jobs(job_id),
+			FOREIGN KEY (skill_id) REFERENCES linkedin_skills(skill_id)
+		)`)
+	return err
+}
+
+// migrateJobExtra adds the JSON encoded extra fields of every job.
+func migrateJobExtra(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN extra TEXT`)
+	return err
+}
+
+// migrateJobDedupGroup adds the near-duplicate group of every job.
+func migrateJobDedupGroup(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN dedup_group TEXT`); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`CREATE INDEX idx_jobs_dedup_group ON jobs(dedup_group)`)
+	return err
+}
+
+// migrateJobFetchedAt adds when every job was last fetched from LinkedIn.
+func migrateJobFetchedAt(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN fetched_at TEXT`)
+	return err
+}
+
+// migrateKeepFirstSeen makes first_seen of searches_jobs immutable, since
+// how long a posting has been live is computed from it.
+func migrateKeepFirstSeen(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TRIGGER searches_jobs_keep_first_seen
+		BEFORE UPDATE OF first_seen ON searches_jobs
+		WHEN NEW.first_seen IS NOT OLD.first_seen
+		BEGIN
+			SELECT RAISE(ABORT, 'first_seen of searches_jobs can not be changed');
+		END`)
+	return err
+}
+
+// migrateJobDescriptionHash adds the hash of every job's description, to
+// tell the postings whose description changed between runs.
+func migrateJobDescriptionHash(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN description_hash TEXT`)
+	return err
+}
+
+// migrateJobAnalyses adds the Gemini analyses of the jobs, as written by the
+// pipeline command when it shares a database with the scraper.
+func migrateJobAnalyses(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS job_analyses (
+			job_id TEXT PRIMARY KEY,
+			model TEXT NOT NULL,
+			analysis TEXT NOT NULL,
+			analyzed_at TEXT NOT NULL,
+			FOREIGN KEY (job_id) REFERENCES jobs(job_id)
+		)`)
+	return err
+}
+
+// migrateJobRoleFamily adds the normalized title and role family of every
+// job.
+func migrateJobRoleFamily(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN normalized_title TEXT`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN role_family TEXT`); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`CREATE INDEX idx_jobs_role_family ON jobs(role_family)`)
+	return err
+}
+
+// migrateJobLocation adds the location LinkedIn lists every job in.
+func migrateJobLocation(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN location TEXT`)
+	return err
+}
+
+// migrateFetchMeta adds the attempts, final status and duration of every job
+// posting fetch, one row per fetch.
+func migrateFetchMeta(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE fetch_meta (
+			job_id TEXT NOT NULL,
+			fetched_at TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			final_status INTEGER NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			error TEXT
+		)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE INDEX idx_fetch_meta_job_id ON fetch_meta(job_id)`)
+	return err
+}
+
+// migrateJobWorkplaceType adds the workplace type LinkedIn lists every job
+// with.
+func migrateJobWorkplaceType(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE jobs ADD COLUMN workplace_type TEXT`)
+	return err
+}
+
+// migrateAnalysisMinYears adds the years of experience parsed from every
+// analysis. The pipeline may have added it already to a shared database.
+func migrateAnalysisMinYears(tx *sql.Tx) error {
+	return addColumnIfMissing(tx, "job_analyses", "min_years_experience", "INTEGER")
+}
+
+// addColumnIfMissing adds column to table when a database created by an older
+// version of the scraper does not have it yet.
+func addColumnIfMissing(db execQuerier, table, column, decl string) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return fmt.Errorf("could not read columns of table '%s': %v", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("could not read columns of table '%s': %v", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read columns of table '%s': %v", table, err)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl)); err != nil {
+		return fmt.Errorf("could not add column '%s' to table '%s': %v", column, table, err)
+	}
+
+	return nil
+}
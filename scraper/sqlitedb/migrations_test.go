@@ -0,0 +1,111 @@
+package sqlitedb
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateFromVersion1(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.sqlite")
+
+	// A database as left by the first versioned scraper
+	db, err := sql.Open("sqlite", sqliteFile)
+	if err != nil {
+		t.Fatalf("could not open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE schema_version (version INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("could not create schema_version table: %v", err)
+	}
+	if err := applyMigration(db, migrations[0]); err != nil {
+		t.Fatalf("could not create version 1 schema: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO jobs (job_id, company, description, title, posted_at, language)
+		VALUES ('4100000001', 'Acme', 'Go developer wanted', 'Backend Developer', '2025-01-02T03:04:05Z', 'en')`)
+	if err != nil {
+		t.Fatalf("could not insert job: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO searches (search_term) VALUES ('golang');
+		INSERT INTO searches_jobs (search_id, job_id, first_seen, last_seen)
+		VALUES (1, '4100000001', '2025-01-02T00:00:00Z', '2025-01-03T00:00:00Z')`)
+	if err != nil {
+		t.Fatalf("could not insert search: %v", err)
+	}
+	db.Close()
+
+	db, err = Open(sqliteFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT version FROM schema_version`).Scan(&version); err != nil {
+		t.Fatalf("could not read schema version: %v", err)
+	}
+	if want := migrations[len(migrations)-1].version; version != want {
+		t.Errorf("schema version = %d, want %d", version, want)
+	}
+
+	var company, title, postedAt, firstSeen string
+	var location, workplaceType sql.NullString
+	err = db.QueryRow(`
+		SELECT j.company, j.title, j.posted_at, j.location, j.workplace_type, sj.first_seen
+		FROM jobs j JOIN searches_jobs sj ON sj.job_id = j.job_id
+		WHERE j.job_id = '4100000001'`).Scan(&company, &title, &postedAt, &location, &workplaceType, &firstSeen)
+	if err != nil {
+		t.Fatalf("could not read migrated job: %v", err)
+	}
+	if company != "Acme" || title != "Backend Developer" || postedAt != "2025-01-02T03:04:05Z" || firstSeen != "2025-01-02T00:00:00Z" {
+		t.Errorf("migrated job = %q, %q, %q, %q; want the values inserted before migrating", company, title, postedAt, firstSeen)
+	}
+	if location.Valid || workplaceType.Valid {
+		t.Errorf("new columns of migrated job = %v, %v; want NULL", location, workplaceType)
+	}
+
+	// Tables added by later migrations are usable
+	_, err = db.Exec(`
+		INSERT INTO job_analyses (job_id, model, analysis, analyzed_at, min_years_experience)
+		VALUES ('4100000001', 'gemini', '{}', '2025-01-04T00:00:00Z', 3)`)
+	if err != nil {
+		t.Errorf("could not insert analysis into migrated database: %v", err)
+	}
+
+	db.Close()
+
+	// Reopening an up to date database is a no-op
+	db, err = Open(sqliteFile)
+	if err != nil {
+		t.Fatalf("could not reopen migrated database: %v", err)
+	}
+	db.Close()
+}
+
+func TestOpenCreatesIndexes(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.sqlite")
+
+	// Opening twice checks the indexes survive a setup of an up to date
+	// database
+	for range 2 {
+		db, err := Open(sqliteFile)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+
+		for _, index := range []struct{ name, table string }{
+			{"idx_searches_jobs_last_seen", "searches_jobs"},
+			{"idx_searches_jobs_job_id", "searches_jobs"},
+			{"idx_jobs_categories_category_id", "jobs_categories"},
+		} {
+			var table string
+			err := db.QueryRow(`SELECT tbl_name FROM sqlite_master WHERE type = 'index' AND name = ?`, index.name).Scan(&table)
+			if err != nil {
+				t.Errorf("could not find index %s: %v", index.name, err)
+			} else if table != index.table {
+				t.Errorf("index %s is on %s, want %s", index.name, table, index.table)
+			}
+		}
+		db.Close()
+	}
+}
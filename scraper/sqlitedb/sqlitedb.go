@@ -0,0 +1,40 @@
+// Package sqlitedb opens the SQLite databases shared by the scraper and the
+// pipeline, with the connection settings and schema migrations both rely on.
+package sqlitedb
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the SQLite database at sqliteFile and migrates it to the latest
+// schema. The pragmas are passed in the DSN so that every pooled connection
+// gets them, not only the first one: WAL journaling and a busy timeout let the
+// scraper, the transformer and the pipeline share the same file without
+// "database is locked" errors.
+func Open(sqliteFile string) (*sql.DB, error) {
+	dsn := sqliteFile +
+		"?_pragma=busy_timeout(5000)" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_pragma=synchronous(NORMAL)" +
+		"&_pragma=foreign_keys(ON)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open SQLite database '%s': %v", sqliteFile, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to SQLite database '%s': %v", sqliteFile, err)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,63 @@
+package sqlitedb
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestOpenConcurrentWriters(t *testing.T) {
+	sqliteFile := filepath.Join(t.TempDir(), "jobs.sqlite")
+
+	// Two handles, as when the scraper and the pipeline share the file
+	first, err := Open(sqliteFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer first.Close()
+	second, err := Open(sqliteFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer second.Close()
+
+	var mode string
+	if err := first.QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil {
+		t.Fatalf("could not read journal mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+
+	const writesPerHandle = 100
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writesPerHandle)
+	for i, db := range []*sql.DB{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range writesPerHandle {
+				_, err := db.Exec(`INSERT INTO companies (company_name) VALUES (?)`, fmt.Sprintf("company %d-%d", i, j))
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent write failed: %v", err)
+	}
+
+	var count int
+	if err := second.QueryRow(`SELECT COUNT(*) FROM companies`).Scan(&count); err != nil {
+		t.Fatalf("could not count companies: %v", err)
+	}
+	if count != 2*writesPerHandle {
+		t.Errorf("got %d companies, want %d", count, 2*writesPerHandle)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loadSynonyms reads a JSON file mapping category names to the search terms
+// they expand to, e.g. {"Backend": ["backend developer", "backend engineer"]}.
+func loadSynonyms(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read synonyms file '%s': %v", path, err)
+	}
+
+	var synonyms map[string][]string
+	if err := json.Unmarshal(data, &synonyms); err != nil {
+		return nil, fmt.Errorf("could not decode synonyms file '%s': %v", path, err)
+	}
+
+	return synonyms, nil
+}
+
+// expandJobCategories merges the search terms of synonyms into the category
+// of the same name, ignoring case, after its explicit terms. Categories only
+// in synonyms are appended sorted by name. Search terms are deduped ignoring
+// case and spacing, keeping the first spelling.
+func expandJobCategories(categories []JobCategory, synonyms map[string][]string) []JobCategory {
+	names := make([]string, 0, len(synonyms))
+	for name := range synonyms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expanded := make([]JobCategory, len(categories))
+	for i, cat := range categories {
+		expanded[i] = JobCategory{Category: cat.Category, SearchTerms: append([]string{}, cat.SearchTerms...)}
+	}
+
+	for _, name := range names {
+		index := -1
+		for i, cat := range expanded {
+			if strings.EqualFold(cat.Category, strings.TrimSpace(name)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			expanded = append(expanded, JobCategory{Category: strings.TrimSpace(name)})
+			index = len(expanded) - 1
+		}
+		expanded[index].SearchTerms = append(expanded[index].SearchTerms, synonyms[name]...)
+	}
+
+	for i := range expanded {
+		expanded[i].SearchTerms = dedupSearchTerms(expanded[i].SearchTerms)
+	}
+
+	return expanded
+}
+
+// dedupSearchTerms drops empty terms and the repeats of a term, ignoring case
+// and spacing.
+func dedupSearchTerms(terms []string) []string {
+	seen := make(map[string]bool)
+	deduped := []string{}
+	for _, term := range terms {
+		term = strings.Join(strings.Fields(term), " ")
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, term)
+	}
+	return deduped
+}
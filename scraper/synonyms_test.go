@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandJobCategories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.json")
+	synonymsJSON := `{
+		"backend": ["backend engineer", "Golang", "  backend   developer "],
+		"QA": ["qa automation", "QA Automation", ""],
+		" Data ": ["data engineer"]
+	}`
+	if err := os.WriteFile(path, []byte(synonymsJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	synonyms, err := loadSynonyms(path)
+	if err != nil {
+		t.Fatalf("loadSynonyms: %v", err)
+	}
+
+	categories := []JobCategory{
+		{Category: "Backend", SearchTerms: []string{"backend developer", "golang"}},
+		{Category: "Frontend", SearchTerms: []string{"react"}},
+	}
+	expanded := expandJobCategories(categories, synonyms)
+
+	want := []JobCategory{
+		{Category: "Backend", SearchTerms: []string{"backend developer", "golang", "backend engineer"}},
+		{Category: "Frontend", SearchTerms: []string{"react"}},
+		{Category: "Data", SearchTerms: []string{"data engineer"}},
+		{Category: "QA", SearchTerms: []string{"qa automation"}},
+	}
+	if !reflect.DeepEqual(expanded, want) {
+		t.Errorf("got %+v, want %+v", expanded, want)
+	}
+	if len(categories[0].SearchTerms) != 2 {
+		t.Errorf("the search terms of the given categories were modified: %q", categories[0].SearchTerms)
+	}
+}
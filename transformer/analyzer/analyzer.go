@@ -0,0 +1,702 @@
+// Package analyzer extracts structured data (seniority, skills, work
+// arrangement, ...) from job descriptions with the Gemini API, sending the
+// jobs in batches sized to fit the model's token limit.
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// --- Configuration Constants ---
+
+// The default model to use.
+const MODEL_NAME = "gemini-2.5-flash-lite"
+
+// The maximum allowed tokens per request to Gemini.
+const MAX_TOKENS_PER_REQUEST = 15000
+
+// A common ratio for estimating tokens from characters (rough estimate: 4 characters per token).
+const TOKEN_TO_CHAR_RATIO = 4
+
+// Estimated overhead for the fixed system prompt and the JSON schema.
+const SYSTEM_OVERHEAD_TOKENS = 2500
+
+// The default maximum number of jobs per request. Past this, models start
+// dropping job IDs from the response even when the prompt fits the token limit.
+const MAX_JOBS_PER_BATCH = 15
+
+// The default number of times a failed batch is split in halves to recover
+// the analyses of the jobs that didn't cause the failure. 4 levels take a
+// batch of MAX_JOBS_PER_BATCH jobs down to single jobs.
+const MAX_SPLIT_DEPTH = 4
+
+// ErrQuotaExhausted is returned once Gemini refuses requests because the API
+// key ran out of quota or lacks permission. Further requests would fail too,
+// so the run should be stopped.
+var ErrQuotaExhausted = errors.New("gemini quota exhausted or permission denied")
+
+// ErrOutputTruncated is returned when the model stopped at its output token
+// limit, leaving the JSON array incomplete. Smaller batches fit.
+var ErrOutputTruncated = errors.New("gemini output truncated at the token limit")
+
+// ContentBlockedError is returned when Gemini refused to answer a batch
+// because of its content, either the prompt or the output it would generate.
+type ContentBlockedError struct {
+	// Reason is Gemini's block or finish reason, e.g. SAFETY or RECITATION.
+	Reason string
+}
+
+func (e *ContentBlockedError) Error() string {
+	return fmt.Sprintf("gemini blocked the content (%s)", e.Reason)
+}
+
+// --- Data Structures ---
+
+// JobInput represents a job object in the input JSON file.
+type JobInput struct {
+	JobID       string `json:"job_id"`
+	Description string `json:"description"`
+	// Language is the language detected by the scraper, empty if unknown.
+	Language string `json:"language,omitempty"`
+	// Category and SearchTerm are the scraper's category and search that
+	// found the job, if known. They are given to the model as context and
+	// echoed into the job's analysis.
+	Category   string `json:"category,omitempty"`
+	SearchTerm string `json:"search_term,omitempty"`
+	// WorkplaceType is where the job is done as LinkedIn lists it, on_site,
+	// hybrid or remote, empty if unknown. When set it is given to the model
+	// and overrides the onsite_hybrid_remote the model extracts.
+	WorkplaceType string `json:"workplace_type,omitempty"`
+}
+
+// JobAnalysis represents the desired structured output for a single job.
+// NOTE: Field names are intentionally lowercase to match the requested JSON schema keys.
+type JobAnalysis struct {
+	JobID              string     `json:"job_id"`
+	Seniority          string     `json:"seniority"`
+	Skills             []string   `json:"skills"`
+	OnsiteHybridRemote string     `json:"onsite_hybrid_remote"`
+	Confidence         Confidence `json:"confidence"`
+	// Category and SearchTerm are copied from the job's JobInput.
+	Category   string `json:"category,omitempty"`
+	SearchTerm string `json:"search_term,omitempty"`
+	// MinYearsExperience is the number of years of experience the job
+	// requires, parsed from its EXPERIENCE_FIELD. Nil when no number of
+	// years was found.
+	MinYearsExperience *int `json:"min_years_experience,omitempty"`
+
+	// Extra holds the fields added through a custom AnalysisConfig that have
+	// no dedicated struct field. They are written back at the top level.
+	Extra map[string]any `json:"-"`
+}
+
+func (a *JobAnalysis) UnmarshalJSON(data []byte) error {
+	type plain JobAnalysis
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, known := range jobAnalysisFields {
+		delete(fields, known)
+	}
+
+	a.Extra = nil
+	if len(fields) > 0 {
+		a.Extra = fields
+	}
+	return nil
+}
+
+func (a JobAnalysis) MarshalJSON() ([]byte, error) {
+	type plain JobAnalysis
+	data, err := json.Marshal(plain(a))
+	if err != nil || len(a.Extra) == 0 {
+		return data, err
+	}
+
+	extra, err := json.Marshal(a.Extra)
+	if err != nil {
+		return nil, err
+	}
+
+	// Splice the extra fields into the object, after the known ones
+	return append(append(data[:len(data)-1], ','), extra[1:]...), nil
+}
+
+// jobAnalysisFields are the JSON keys mapped to JobAnalysis struct fields.
+var jobAnalysisFields = []string{"job_id", "seniority", "skills", "onsite_hybrid_remote", "confidence", "category", "search_term", "min_years_experience"}
+
+// Confidence holds the model's self-reported confidence (0 to 1) in the
+// extracted fields. Models that do not report it leave every value at zero.
+type Confidence struct {
+	Overall            float64 `json:"overall"`
+	Seniority          float64 `json:"seniority"`
+	Skills             float64 `json:"skills"`
+	OnsiteHybridRemote float64 `json:"onsite_hybrid_remote"`
+}
+
+// ContentGenerator generates model responses. The Models service of a
+// *genai.Client satisfies it; tests can provide a fake returning canned
+// responses instead of calling the API.
+type ContentGenerator interface {
+	GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
+}
+
+// Analyzer sends jobs to Gemini and parses the analyses it returns.
+type Analyzer struct {
+	Generator ContentGenerator
+	Model     string
+	Config    *AnalysisConfig
+
+	// SkillAliases maps alternative spellings of a skill to its canonical
+	// name, applied to every extracted skill.
+	SkillAliases map[string]string
+	Backoff      *Backoff
+
+	// Limits sizes the batches sent by Analyze.
+	Limits BatchLimits
+
+	// Verbose logs the prompt and the raw model output of every batch.
+	Verbose bool
+
+	// DumpDir, when set, is where the raw model output of a batch that can't
+	// be parsed is written, as batch-<BatchID>.txt.
+	DumpDir string
+
+	// MaxSplitDepth is how many times RecoverBatch halves a failed batch,
+	// 0 disables the recovery.
+	MaxSplitDepth int
+
+	// Blocked records the jobs RecoverBatch dropped because Gemini blocked
+	// their content, by job ID, with the reason. It is only safe to read
+	// once no batch is being processed.
+	Blocked   map[string]string
+	blockedMu sync.Mutex
+}
+
+// New returns an Analyzer using generator, usually the Models service of a
+// *genai.Client, with the default model, analysis config, skill aliases and
+// retry backoff.
+func New(generator ContentGenerator) *Analyzer {
+	return &Analyzer{
+		Generator:     generator,
+		Model:         MODEL_NAME,
+		Config:        DefaultAnalysisConfig(),
+		SkillAliases:  DefaultSkillAliases(),
+		Backoff:       NewBackoff(),
+		Limits:        DefaultBatchLimits(),
+		MaxSplitDepth: MAX_SPLIT_DEPTH,
+		Blocked:       make(map[string]string),
+	}
+}
+
+// Analyze batches jobs and processes every batch, returning the analyses of
+// all the batches that succeeded. Failed batches are skipped and reported
+// together in the returned error. Once the quota is exhausted the remaining
+// batches are not sent, and the error wraps ErrQuotaExhausted.
+func (a *Analyzer) Analyze(ctx context.Context, jobs []JobInput) ([]JobAnalysis, error) {
+	batches := CreateBatches(jobs, a.Limits)
+
+	var results []JobAnalysis
+	var errs []error
+	for i, batch := range batches {
+		batchResults, err := a.RecoverBatch(ctx, batch)
+		results = append(results, batchResults...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("batch %d: %w", i+1, err))
+			if errors.Is(err, ErrQuotaExhausted) {
+				break
+			}
+		}
+	}
+
+	SortByInput(results, jobs)
+	return results, errors.Join(errs...)
+}
+
+// SortByInput orders results like the jobs they analyze, so the output of two
+// runs over the same input can be compared line by line. Analyses of job IDs
+// not in jobs go last, ordered by job ID.
+func SortByInput(results []JobAnalysis, jobs []JobInput) {
+	index := make(map[string]int, len(jobs))
+	for i, job := range jobs {
+		if _, ok := index[job.JobID]; !ok {
+			index[job.JobID] = i
+		}
+	}
+
+	position := func(jobID string) int {
+		if i, ok := index[jobID]; ok {
+			return i
+		}
+		return len(jobs)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		pi, pj := position(results[i].JobID), position(results[j].JobID)
+		if pi != pj {
+			return pi < pj
+		}
+		return pi == len(jobs) && results[i].JobID < results[j].JobID
+	})
+}
+
+// BatchLimits bound the size of a single request.
+type BatchLimits struct {
+	// MaxTokensPerRequest is the token budget of a request, of which
+	// SystemOverheadTokens go to the system prompt and the JSON schema.
+	MaxTokensPerRequest  int
+	SystemOverheadTokens int
+
+	// MaxJobs caps the jobs sent in a single request, 0 means only the token
+	// limit applies.
+	MaxJobs int
+}
+
+// DefaultBatchLimits returns the limits tuned for MODEL_NAME.
+func DefaultBatchLimits() BatchLimits {
+	return BatchLimits{
+		MaxTokensPerRequest:  MAX_TOKENS_PER_REQUEST,
+		SystemOverheadTokens: SYSTEM_OVERHEAD_TOKENS,
+		MaxJobs:              MAX_JOBS_PER_BATCH,
+	}
+}
+
+// Validate reports limits that leave no room for the job descriptions.
+func (l BatchLimits) Validate() error {
+	if l.SystemOverheadTokens < 0 || l.MaxJobs < 0 {
+		return fmt.Errorf("batch limits can't be negative")
+	}
+	if l.SystemOverheadTokens >= l.MaxTokensPerRequest {
+		return fmt.Errorf("system overhead (%d tokens) must be lower than the tokens per request (%d)",
+			l.SystemOverheadTokens, l.MaxTokensPerRequest)
+	}
+	return nil
+}
+
+// CreateBatches groups jobs into batches based on a calculated maximum character limit.
+// A batch is also finalized once it holds limits.MaxJobs jobs, unless it is 0.
+func CreateBatches(jobs []JobInput, limits BatchLimits) [][]JobInput {
+	batcher := NewBatcher(limits)
+	log.Printf("Maximum estimated input characters per request: %d (approx %d tokens).\n",
+		batcher.maxInputChars, limits.MaxTokensPerRequest-limits.SystemOverheadTokens)
+
+	var batches [][]JobInput
+	for _, job := range jobs {
+		if batch := batcher.Add(job); batch != nil {
+			batches = append(batches, batch)
+		}
+	}
+
+	// Add the last batch if it's not empty
+	if batch := batcher.Flush(); batch != nil {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// Batcher groups jobs into batches as they arrive, with the limits of
+// CreateBatches, for callers that don't have every job up front.
+type Batcher struct {
+	maxJobs       int
+	maxInputChars int
+
+	currentBatch          []JobInput
+	currentBatchCharCount int
+}
+
+// NewBatcher returns an empty Batcher sizing batches with limits.
+func NewBatcher(limits BatchLimits) *Batcher {
+	// Calculate the maximum characters allowed for the *input* descriptions
+	maxInputTokens := limits.MaxTokensPerRequest - limits.SystemOverheadTokens
+	maxInputChars := maxInputTokens * TOKEN_TO_CHAR_RATIO
+	if maxInputChars <= 0 {
+		log.Printf("Warning: Calculated max input characters is non-positive (%d). Using a default of 4000.\n", maxInputChars)
+		maxInputChars = 4000
+	}
+
+	return &Batcher{maxJobs: limits.MaxJobs, maxInputChars: maxInputChars}
+}
+
+// Add adds job to the current batch. When job doesn't fit in it, the current
+// batch is returned as complete and job starts the next one; otherwise Add
+// returns nil.
+func (b *Batcher) Add(job JobInput) []JobInput {
+	jobCharCount := len(job.Description)
+
+	// If adding the current job description exceeds either limit, finalize the current batch
+	var complete []JobInput
+	exceedsChars := b.currentBatchCharCount+jobCharCount > b.maxInputChars
+	exceedsJobs := b.maxJobs > 0 && len(b.currentBatch) >= b.maxJobs
+	if (exceedsChars || exceedsJobs) && len(b.currentBatch) > 0 {
+		complete = b.Flush()
+	}
+
+	// Add the job to the current batch
+	b.currentBatch = append(b.currentBatch, job)
+	b.currentBatchCharCount += jobCharCount
+
+	return complete
+}
+
+// Flush returns the current batch, or nil when it's empty, and starts a new
+// one.
+func (b *Batcher) Flush() []JobInput {
+	batch := b.currentBatch
+	b.currentBatch = nil
+	b.currentBatchCharCount = 0
+	return batch
+}
+
+// BatchID returns a short stable identifier of batch, derived from its job
+// IDs regardless of their order, so the same jobs get the same ID every run.
+func BatchID(batch []JobInput) string {
+	ids := make([]string, len(batch))
+	for i, job := range batch {
+		ids[i] = job.JobID
+	}
+	sort.Strings(ids)
+
+	sum := sha256.Sum256([]byte(strings.Join(ids, "\n")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// BatchEstimate is the estimated input size of a single API call.
+type BatchEstimate struct {
+	Jobs   int
+	Chars  int
+	Tokens int
+}
+
+// EstimateBatches estimates the input tokens of each batch the same way
+// CreateBatches sizes them, plus the fixed system overhead of every request.
+func EstimateBatches(batches [][]JobInput, limits BatchLimits) []BatchEstimate {
+	estimates := make([]BatchEstimate, 0, len(batches))
+	for _, batch := range batches {
+		chars := 0
+		for _, job := range batch {
+			chars += len(job.Description)
+		}
+
+		estimates = append(estimates, BatchEstimate{
+			Jobs:   len(batch),
+			Chars:  chars,
+			Tokens: chars/TOKEN_TO_CHAR_RATIO + limits.SystemOverheadTokens,
+		})
+	}
+	return estimates
+}
+
+// OUTPUT_WRAPPER_FIELDS are the fields of the objects the model sometimes
+// wraps the analyses array in, despite the response schema asking for a bare
+// array.
+var OUTPUT_WRAPPER_FIELDS = []string{"jobs", "analyses", "results", "data", "items"}
+
+// parseBatchOutput decodes the analyses of the model's output, either a bare
+// array or an object holding the array in one of OUTPUT_WRAPPER_FIELDS. It
+// returns the wrapper field used, empty for a bare array, and the error of
+// decoding it as an array when no shape matches.
+func parseBatchOutput(data []byte) ([]JobAnalysis, string, error) {
+	var analyses []JobAnalysis
+	arrayErr := json.Unmarshal(data, &analyses)
+	if arrayErr == nil {
+		return analyses, "", nil
+	}
+
+	var wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, "", arrayErr
+	}
+	for _, field := range OUTPUT_WRAPPER_FIELDS {
+		raw, ok := wrapped[field]
+		if !ok {
+			continue
+		}
+		analyses = nil
+		if err := json.Unmarshal(raw, &analyses); err == nil && analyses != nil {
+			return analyses, field, nil
+		}
+	}
+
+	return nil, "", arrayErr
+}
+
+// ProcessBatch sends a batch of job descriptions to the Gemini API and parses the array response.
+func (a *Analyzer) ProcessBatch(ctx context.Context, batchJobs []JobInput) ([]JobAnalysis, error) {
+	batchID := BatchID(batchJobs)
+
+	// 1. Construct the combined prompt
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("Analyze the following job descriptions and provide the analysis for ALL of them. The jobs are separated by '---JOBBREAK---'.\n\n")
+
+	// Append all job descriptions and their IDs
+	for i, job := range batchJobs {
+		promptBuilder.WriteString(fmt.Sprintf("JobID: %s\n", job.JobID))
+		if job.Category != "" {
+			promptBuilder.WriteString(fmt.Sprintf("Expected role category: %s\n", job.Category))
+		}
+		if job.SearchTerm != "" {
+			promptBuilder.WriteString(fmt.Sprintf("Found searching for: %s\n", job.SearchTerm))
+		}
+		if job.WorkplaceType != "" {
+			promptBuilder.WriteString(fmt.Sprintf("Work arrangement listed by LinkedIn: %s\n", job.WorkplaceType))
+		}
+		promptBuilder.WriteString(fmt.Sprintf("Description:\n%s\n", job.Description))
+		if i < len(batchJobs)-1 {
+			promptBuilder.WriteString("\n---JOBBREAK---\n\n")
+		}
+	}
+
+	// 2. Build the JSON Schema from the analysis configuration
+	schema, err := a.Config.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("invalid analysis config: %w", err)
+	}
+
+	if a.Verbose {
+		log.Printf("[batch %s] Prompt:\n%s\n", batchID, promptBuilder.String())
+	}
+
+	// 3. Call the API (SDK handles retry/backoff logic for most transient errors)
+	var resp *genai.GenerateContentResponse
+	var lastErr error
+	const maxRetries = 3
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, lastErr = a.Generator.GenerateContent(ctx,
+			a.Model,
+			genai.Text(promptBuilder.String()),
+			&genai.GenerateContentConfig{
+				ResponseMIMEType:  "application/json",
+				ResponseSchema:    schema,
+				SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: a.Config.SystemInstruction}}},
+			},
+		)
+		if lastErr == nil {
+			break // Success
+		}
+
+		// Retrying can't help once the quota is gone
+		if isQuotaError(lastErr) {
+			return nil, fmt.Errorf("%w: %v", ErrQuotaExhausted, lastErr)
+		}
+
+		// Neither can it once the run is canceled or past its deadline
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("gemini API call aborted: %w", context.Cause(ctx))
+		}
+
+		if attempt < maxRetries-1 {
+			delay := a.Backoff.Delay(attempt)
+			log.Printf("[batch %s] Attempt %d failed: %v. Retrying in %v...\n", batchID, attempt+1, lastErr, delay)
+			// Exponential backoff with full jitter
+			if err := a.Backoff.wait(ctx, delay); err != nil {
+				return nil, fmt.Errorf("gemini API call aborted after %d attempts: %w (last error: %v)", attempt+1, err, lastErr)
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("gemini API call failed after %d attempts: %w", maxRetries, lastErr)
+	}
+
+	// 4. Extract and Parse the JSON content, unless the model stopped early
+	if err := finishError(resp); err != nil {
+		log.Printf("[batch %s] ERROR: %v\n", batchID, err)
+		return nil, err
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("gemini API returned no candidates or content in response")
+	}
+
+	if a.Verbose {
+		log.Printf("[batch %s] Raw model output:\n%s\n", batchID, resp.Text())
+	}
+
+	batchAnalysis, wrapper, err := parseBatchOutput([]byte(resp.Text()))
+	if err != nil {
+		// Log the problematic JSON for debugging
+		log.Printf("[batch %s] ERROR: Failed to unmarshal the model's JSON output. Raw output:\n%s\n", batchID, resp.Text())
+		if a.DumpDir != "" {
+			a.dumpRawOutput(batchID, resp.Text())
+		}
+		return nil, fmt.Errorf("failed to unmarshal model's JSON output: %w", err)
+	}
+	if wrapper != "" {
+		log.Printf("[batch %s] WARNING: The model wrapped the analyses array in an object, read from its %q field.\n", batchID, wrapper)
+	}
+
+	seniorityLevels := a.Config.SeniorityLevels()
+	for i := range batchAnalysis {
+		normalizeAnalysis(&batchAnalysis[i], a.SkillAliases)
+		batchAnalysis[i].Seniority = normalizeSeniority(batchAnalysis[i].Seniority, seniorityLevels)
+		batchAnalysis[i].MinYearsExperience = minYearsExperience(batchAnalysis[i].Extra)
+	}
+	echoSearchContext(batchAnalysis, batchJobs)
+	applyWorkplaceTypes(batchAnalysis, batchJobs, batchID)
+
+	log.Printf("[batch %s] Batch processed successfully. Received analysis for %d jobs.\n", batchID, len(batchAnalysis))
+	return batchAnalysis, nil
+}
+
+// echoSearchContext copies the Category and SearchTerm of every job into its
+// analysis, overwriting whatever the model returned for them.
+func echoSearchContext(analyses []JobAnalysis, jobs []JobInput) {
+	inputs := make(map[string]JobInput, len(jobs))
+	for _, job := range jobs {
+		inputs[job.JobID] = job
+	}
+
+	for i := range analyses {
+		job := inputs[analyses[i].JobID]
+		analyses[i].Category = job.Category
+		analyses[i].SearchTerm = job.SearchTerm
+	}
+}
+
+// applyWorkplaceTypes sets the OnsiteHybridRemote of every analysis whose job
+// has a WorkplaceType listed by LinkedIn to it, logging when the model
+// extracted a different one.
+func applyWorkplaceTypes(analyses []JobAnalysis, jobs []JobInput, batchID string) {
+	listed := make(map[string]string, len(jobs))
+	for _, job := range jobs {
+		if job.WorkplaceType != "" {
+			listed[job.JobID] = job.WorkplaceType
+		}
+	}
+
+	for i := range analyses {
+		workplaceType, ok := listed[analyses[i].JobID]
+		if !ok {
+			continue
+		}
+		if extracted := analyses[i].OnsiteHybridRemote; extracted != "" && extracted != workplaceType {
+			log.Printf("[batch %s] Job %s is listed as %s but the model extracted %s, keeping the listed one.\n",
+				batchID, analyses[i].JobID, workplaceType, extracted)
+		}
+		analyses[i].OnsiteHybridRemote = workplaceType
+	}
+}
+
+// RecoverBatch processes batch like ProcessBatch, but when it fails the batch
+// is split in halves that are processed on their own, recursively up to
+// a.MaxSplitDepth times, so a job that makes the request fail (e.g. by
+// tripping a safety filter) only loses its own analysis. It returns the
+// analyses of every part that succeeded along with the errors of the ones
+// that failed. Once the quota is exhausted no more parts are sent.
+func (a *Analyzer) RecoverBatch(ctx context.Context, batch []JobInput) ([]JobAnalysis, error) {
+	return a.recoverBatch(ctx, batch, 0)
+}
+
+func (a *Analyzer) recoverBatch(ctx context.Context, batch []JobInput, depth int) ([]JobAnalysis, error) {
+	results, err := a.ProcessBatch(ctx, batch)
+	if err == nil || errors.Is(err, ErrQuotaExhausted) || ctx.Err() != nil {
+		return results, err
+	}
+	var blocked *ContentBlockedError
+	if len(batch) == 1 && errors.As(err, &blocked) {
+		log.Printf("Dropping job %s: %v\n", batch[0].JobID, err)
+		a.blockedMu.Lock()
+		if a.Blocked != nil {
+			a.Blocked[batch[0].JobID] = blocked.Reason
+		}
+		a.blockedMu.Unlock()
+	}
+	if len(batch) == 1 || depth >= a.MaxSplitDepth {
+		return nil, fmt.Errorf("jobs %s: %w", strings.Join(jobIDs(batch), ", "), err)
+	}
+
+	half := len(batch) / 2
+	log.Printf("[batch %s] Splitting the failed batch into batches %s and %s to recover its jobs.\n",
+		BatchID(batch), BatchID(batch[:half]), BatchID(batch[half:]))
+
+	results, firstErr := a.recoverBatch(ctx, batch[:half], depth+1)
+	if errors.Is(firstErr, ErrQuotaExhausted) {
+		return results, firstErr
+	}
+	secondResults, secondErr := a.recoverBatch(ctx, batch[half:], depth+1)
+	return append(results, secondResults...), errors.Join(firstErr, secondErr)
+}
+
+// finishError returns the error of a response the model didn't finish
+// normally: blocked content or output truncated at the token limit.
+func finishError(resp *genai.GenerateContentResponse) error {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return &ContentBlockedError{Reason: string(resp.PromptFeedback.BlockReason)}
+	}
+	if len(resp.Candidates) == 0 {
+		return nil
+	}
+
+	switch reason := resp.Candidates[0].FinishReason; reason {
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation, genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent, genai.FinishReasonSPII:
+		return &ContentBlockedError{Reason: string(reason)}
+	case genai.FinishReasonMaxTokens:
+		return ErrOutputTruncated
+	}
+	return nil
+}
+
+func jobIDs(batch []JobInput) []string {
+	ids := make([]string, len(batch))
+	for i, job := range batch {
+		ids[i] = job.JobID
+	}
+	return ids
+}
+
+// isQuotaError reports whether err is Gemini refusing the API key, either for
+// lack of permission or because its quota is exhausted. Per minute rate limits
+// are left out since they clear up after a short backoff.
+func isQuotaError(err error) bool {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		var apiErrPtr *genai.APIError
+		if !errors.As(err, &apiErrPtr) {
+			return false
+		}
+		apiErr = *apiErrPtr
+	}
+
+	switch apiErr.Status {
+	case "PERMISSION_DENIED":
+		return true
+	case "RESOURCE_EXHAUSTED":
+		return !strings.Contains(strings.ToLower(apiErr.Message), "per minute")
+	}
+	return false
+}
+
+// dumpRawOutput writes the raw output of the batch batchID to a.DumpDir.
+func (a *Analyzer) dumpRawOutput(batchID, output string) {
+	if err := os.MkdirAll(a.DumpDir, 0755); err != nil {
+		log.Printf("[batch %s] ERROR creating dump directory: %v\n", batchID, err)
+		return
+	}
+
+	path := filepath.Join(a.DumpDir, "batch-"+batchID+".txt")
+	if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+		log.Printf("[batch %s] ERROR dumping raw output: %v\n", batchID, err)
+		return
+	}
+	log.Printf("[batch %s] Raw output written to %s.\n", batchID, path)
+}
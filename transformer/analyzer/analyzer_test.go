@@ -0,0 +1,455 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// promptJobID matches the JobID lines ProcessBatch writes in the prompt.
+var promptJobID = regexp.MustCompile(`(?m)^JobID: (\S+)$`)
+
+// fakeGenerator is a ContentGenerator answering every request with respond,
+// called with the IDs of the jobs in the prompt, instead of calling Gemini.
+type fakeGenerator struct {
+	respond func(jobIDs []string) (*genai.GenerateContentResponse, error)
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (g *fakeGenerator) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	var ids []string
+	for _, match := range promptJobID.FindAllStringSubmatch(contents[0].Parts[0].Text, -1) {
+		ids = append(ids, match[1])
+	}
+
+	g.mu.Lock()
+	g.calls = append(g.calls, ids)
+	g.mu.Unlock()
+
+	return g.respond(ids)
+}
+
+// callCount returns how many requests g answered.
+func (g *fakeGenerator) callCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.calls)
+}
+
+// textResponse returns a response whose single candidate finished normally
+// with text.
+func textResponse(text string) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      genai.NewContentFromText(text, genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}
+}
+
+// analysesResponse answers with an analysis of every job in jobIDs, spelled
+// the way models often do before normalization.
+func analysesResponse(jobIDs []string) (*genai.GenerateContentResponse, error) {
+	analyses := make([]map[string]any, len(jobIDs))
+	for i, id := range jobIDs {
+		analyses[i] = map[string]any{
+			"job_id":               id,
+			"seniority":            "Sr",
+			"skills":               []string{"Golang", " go ", "K8s", ""},
+			"onsite_hybrid_remote": "remote",
+		}
+	}
+
+	data, err := json.Marshal(analyses)
+	if err != nil {
+		return nil, err
+	}
+	return textResponse(string(data)), nil
+}
+
+// newTestAnalyzer returns an Analyzer using generator that retries without
+// sleeping.
+func newTestAnalyzer(generator ContentGenerator) *Analyzer {
+	a := New(generator)
+	a.Backoff.After = func(time.Duration) <-chan time.Time { return time.After(0) }
+	return a
+}
+
+// testJobs returns n jobs with IDs job-01 to job-<n>.
+func testJobs(n int) []JobInput {
+	jobs := make([]JobInput, n)
+	for i := range jobs {
+		id := fmt.Sprintf("job-%02d", i+1)
+		jobs[i] = JobInput{JobID: id, Description: "Backend developer with Go experience, job " + id}
+	}
+	return jobs
+}
+
+func TestProcessBatch(t *testing.T) {
+	generator := &fakeGenerator{respond: analysesResponse}
+	a := newTestAnalyzer(generator)
+
+	jobs := testJobs(2)
+	jobs[1].Category = "backend"
+	jobs[1].SearchTerm = "golang"
+	results, err := a.ProcessBatch(context.Background(), jobs)
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d analyses, want 2", len(results))
+	}
+	for i, result := range results {
+		if result.JobID != jobs[i].JobID {
+			t.Errorf("analysis %d is of job %q, want %q", i, result.JobID, jobs[i].JobID)
+		}
+		if want := []string{"go", "kubernetes"}; !slices.Equal(result.Skills, want) {
+			t.Errorf("skills of job %s = %q, want %q", result.JobID, result.Skills, want)
+		}
+		if result.Seniority != "Senior" {
+			t.Errorf("seniority of job %s = %q, want Senior", result.JobID, result.Seniority)
+		}
+	}
+	if results[1].Category != "backend" || results[1].SearchTerm != "golang" {
+		t.Errorf("search context of job %s = %q, %q; want it echoed from the input", results[1].JobID, results[1].Category, results[1].SearchTerm)
+	}
+}
+
+func TestProcessBatchRetriesErrors(t *testing.T) {
+	generator := &fakeGenerator{}
+	generator.respond = func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		if generator.callCount() < 3 {
+			return nil, errors.New("service unavailable")
+		}
+		return analysesResponse(jobIDs)
+	}
+	a := newTestAnalyzer(generator)
+
+	results, err := a.ProcessBatch(context.Background(), testJobs(1))
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(results) != 1 || generator.callCount() != 3 {
+		t.Errorf("got %d analyses after %d calls, want 1 after 3", len(results), generator.callCount())
+	}
+}
+
+func TestProcessBatchEmptyCandidates(t *testing.T) {
+	generator := &fakeGenerator{respond: func([]string) (*genai.GenerateContentResponse, error) {
+		return &genai.GenerateContentResponse{}, nil
+	}}
+	a := newTestAnalyzer(generator)
+
+	results, err := a.ProcessBatch(context.Background(), testJobs(2))
+	if err == nil || !strings.Contains(err.Error(), "no candidates") {
+		t.Errorf("got error %v, want one about the missing candidates", err)
+	}
+	if results != nil {
+		t.Errorf("got analyses %v, want none", results)
+	}
+}
+
+func TestProcessBatchMalformedJSON(t *testing.T) {
+	generator := &fakeGenerator{respond: func([]string) (*genai.GenerateContentResponse, error) {
+		return textResponse(`[{"job_id": "job-01", "skills": [`), nil
+	}}
+	a := newTestAnalyzer(generator)
+	a.DumpDir = t.TempDir()
+
+	jobs := testJobs(2)
+	_, err := a.ProcessBatch(context.Background(), jobs)
+	if err == nil || !strings.Contains(err.Error(), "failed to unmarshal") {
+		t.Fatalf("got error %v, want one about the malformed output", err)
+	}
+
+	dumped, err := os.ReadFile(filepath.Join(a.DumpDir, "batch-"+BatchID(jobs)+".txt"))
+	if err != nil {
+		t.Fatalf("could not read the dumped output: %v", err)
+	}
+	if string(dumped) != `[{"job_id": "job-01", "skills": [` {
+		t.Errorf("dumped output = %q, want the raw model output", dumped)
+	}
+}
+
+func TestProcessBatchWrappedOutput(t *testing.T) {
+	generator := &fakeGenerator{respond: func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		return textResponse(`{"analyses": [{"job_id": "` + jobIDs[0] + `", "skills": []}]}`), nil
+	}}
+	a := newTestAnalyzer(generator)
+
+	results, err := a.ProcessBatch(context.Background(), testJobs(1))
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(results) != 1 || results[0].JobID != "job-01" {
+		t.Errorf("got analyses %+v, want the one of job-01", results)
+	}
+}
+
+func TestProcessBatchConfidence(t *testing.T) {
+	generator := &fakeGenerator{respond: func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		return textResponse(`[
+			{"job_id": "job-01", "skills": [], "confidence": {"overall": 0.9, "seniority": 0.5, "skills": 0.8, "onsite_hybrid_remote": 1}},
+			{"job_id": "job-02", "skills": []}
+		]`), nil
+	}}
+	a := newTestAnalyzer(generator)
+
+	results, err := a.ProcessBatch(context.Background(), testJobs(2))
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d analyses, want 2", len(results))
+	}
+	if want := (Confidence{Overall: 0.9, Seniority: 0.5, Skills: 0.8, OnsiteHybridRemote: 1}); results[0].Confidence != want {
+		t.Errorf("confidence of job-01 = %+v, want %+v", results[0].Confidence, want)
+	}
+	if results[1].Confidence != (Confidence{}) {
+		t.Errorf("confidence of job-02 = %+v, want zero without one in the output", results[1].Confidence)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	generator := &fakeGenerator{respond: func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		// Answer in reverse order, which Analyze must undo
+		reversed := slices.Clone(jobIDs)
+		slices.Reverse(reversed)
+		return analysesResponse(reversed)
+	}}
+	a := newTestAnalyzer(generator)
+	a.Limits.MaxJobs = 2
+
+	jobs := testJobs(5)
+	results, err := a.Analyze(context.Background(), jobs)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if generator.callCount() != 3 {
+		t.Errorf("sent %d batches, want 3", generator.callCount())
+	}
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.JobID)
+	}
+	if want := jobIDs(jobs); !slices.Equal(ids, want) {
+		t.Errorf("got analyses of %q, want %q in input order", ids, want)
+	}
+}
+
+func TestAnalyzeStopsWhenQuotaExhausted(t *testing.T) {
+	generator := &fakeGenerator{respond: func([]string) (*genai.GenerateContentResponse, error) {
+		return nil, genai.APIError{Code: 429, Status: "RESOURCE_EXHAUSTED", Message: "Quota exceeded for the day"}
+	}}
+	a := newTestAnalyzer(generator)
+	a.Limits.MaxJobs = 1
+
+	results, err := a.Analyze(context.Background(), testJobs(3))
+	if !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("got error %v, want ErrQuotaExhausted", err)
+	}
+	if len(results) != 0 || generator.callCount() != 1 {
+		t.Errorf("got %d analyses after %d calls, want none after 1", len(results), generator.callCount())
+	}
+}
+
+func TestCreateBatches(t *testing.T) {
+	limits := BatchLimits{MaxTokensPerRequest: 110, SystemOverheadTokens: 10, MaxJobs: 3}
+	jobs := []JobInput{
+		{JobID: "1", Description: strings.Repeat("a", 150)},
+		{JobID: "2", Description: strings.Repeat("a", 150)},
+		{JobID: "3", Description: strings.Repeat("a", 150)}, // past the 400 characters
+		{JobID: "4", Description: "short"},
+		{JobID: "5", Description: "short"},
+		{JobID: "6", Description: "short"}, // past MaxJobs
+	}
+
+	var sizes []int
+	for _, batch := range CreateBatches(jobs, limits) {
+		sizes = append(sizes, len(batch))
+	}
+	if want := []int{2, 3, 1}; !slices.Equal(sizes, want) {
+		t.Errorf("got batches of %v jobs, want %v", sizes, want)
+	}
+}
+
+func TestCreateBatchesLargerTokenLimit(t *testing.T) {
+	jobs := make([]JobInput, 12)
+	for i := range jobs {
+		jobs[i] = JobInput{JobID: fmt.Sprint(i + 1), Description: strings.Repeat("a", 100)}
+	}
+
+	batchSizes := func(limits BatchLimits) []int {
+		if err := limits.Validate(); err != nil {
+			t.Fatalf("Validate(%+v): %v", limits, err)
+		}
+		var sizes []int
+		for _, batch := range CreateBatches(jobs, limits) {
+			sizes = append(sizes, len(batch))
+		}
+		return sizes
+	}
+
+	// 100 and 400 tokens left for the descriptions, 400 and 1600 characters
+	small := batchSizes(BatchLimits{MaxTokensPerRequest: 110, SystemOverheadTokens: 10})
+	large := batchSizes(BatchLimits{MaxTokensPerRequest: 410, SystemOverheadTokens: 10})
+	if want := []int{4, 4, 4}; !slices.Equal(small, want) {
+		t.Errorf("small limit gave batches of %v jobs, want %v", small, want)
+	}
+	if want := []int{12}; !slices.Equal(large, want) {
+		t.Errorf("large limit gave batches of %v jobs, want %v", large, want)
+	}
+
+	if err := (BatchLimits{MaxTokensPerRequest: 100, SystemOverheadTokens: 100}).Validate(); err == nil {
+		t.Error("Validate with the overhead taking every token succeeded, want an error")
+	}
+}
+
+func TestBatchID(t *testing.T) {
+	jobs := testJobs(3)
+	reordered := []JobInput{jobs[2], jobs[0], jobs[1]}
+
+	if BatchID(jobs) != BatchID(reordered) {
+		t.Error("BatchID depends on the order of the jobs")
+	}
+	if BatchID(jobs) == BatchID(jobs[:2]) {
+		t.Error("BatchID is the same for different jobs")
+	}
+}
+
+// poisonResponse answers like analysesResponse, except for batches holding
+// the job poison, which Gemini blocks.
+func poisonResponse(poison string) func([]string) (*genai.GenerateContentResponse, error) {
+	return func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		if slices.Contains(jobIDs, poison) {
+			return &genai.GenerateContentResponse{
+				PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonSafety},
+			}, nil
+		}
+		return analysesResponse(jobIDs)
+	}
+}
+
+func TestRecoverBatch(t *testing.T) {
+	generator := &fakeGenerator{respond: poisonResponse("job-06")}
+	a := newTestAnalyzer(generator)
+
+	jobs := testJobs(15)
+	results, err := a.RecoverBatch(context.Background(), jobs)
+
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) {
+		t.Errorf("got error %v, want a ContentBlockedError", err)
+	}
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.JobID)
+	}
+	want := slices.DeleteFunc(jobIDs(jobs), func(id string) bool { return id == "job-06" })
+	if !slices.Equal(ids, want) {
+		t.Errorf("recovered the analyses of %q, want every job but job-06", ids)
+	}
+	if reason := a.Blocked["job-06"]; reason != string(genai.BlockedReasonSafety) {
+		t.Errorf("recorded job-06 as blocked for %q, want %s", reason, genai.BlockedReasonSafety)
+	}
+	if len(a.Blocked) != 1 {
+		t.Errorf("recorded %d blocked jobs, want 1", len(a.Blocked))
+	}
+}
+
+func TestRecoverBatchMaxSplitDepth(t *testing.T) {
+	generator := &fakeGenerator{respond: poisonResponse("job-01")}
+	a := newTestAnalyzer(generator)
+	a.MaxSplitDepth = 1
+
+	results, err := a.RecoverBatch(context.Background(), testJobs(8))
+	if err == nil {
+		t.Error("RecoverBatch succeeded, want the error of the poisoned half")
+	}
+	// The whole batch, then each half once
+	if generator.callCount() != 3 {
+		t.Errorf("sent %d requests, want 3", generator.callCount())
+	}
+	if len(results) != 4 {
+		t.Errorf("recovered %d analyses, want the 4 of the clean half", len(results))
+	}
+}
+
+// finishedResponse returns a response whose single candidate stopped for
+// reason without any content.
+func finishedResponse(reason genai.FinishReason) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{FinishReason: reason}},
+	}
+}
+
+func TestProcessBatchSafetyFinishReason(t *testing.T) {
+	generator := &fakeGenerator{respond: func([]string) (*genai.GenerateContentResponse, error) {
+		return finishedResponse(genai.FinishReasonSafety), nil
+	}}
+	a := newTestAnalyzer(generator)
+
+	_, err := a.ProcessBatch(context.Background(), testJobs(2))
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) || blocked.Reason != string(genai.FinishReasonSafety) {
+		t.Fatalf("got error %v, want a ContentBlockedError for SAFETY", err)
+	}
+	if generator.callCount() != 1 {
+		t.Errorf("sent %d requests, want 1: blocked content is not retried as is", generator.callCount())
+	}
+}
+
+func TestRecoverBatchDropsSafetyBlockedJob(t *testing.T) {
+	generator := &fakeGenerator{respond: func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		if slices.Contains(jobIDs, "job-02") {
+			return finishedResponse(genai.FinishReasonSafety), nil
+		}
+		return analysesResponse(jobIDs)
+	}}
+	a := newTestAnalyzer(generator)
+
+	results, _ := a.RecoverBatch(context.Background(), testJobs(4))
+	if len(results) != 3 {
+		t.Errorf("recovered %d analyses, want 3", len(results))
+	}
+	if want := map[string]string{"job-02": string(genai.FinishReasonSafety)}; !maps.Equal(a.Blocked, want) {
+		t.Errorf("blocked jobs = %v, want %v", a.Blocked, want)
+	}
+}
+
+func TestRecoverBatchSplitsTruncatedOutput(t *testing.T) {
+	generator := &fakeGenerator{respond: func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		if len(jobIDs) > 2 {
+			return finishedResponse(genai.FinishReasonMaxTokens), nil
+		}
+		return analysesResponse(jobIDs)
+	}}
+	a := newTestAnalyzer(generator)
+
+	results, err := a.RecoverBatch(context.Background(), testJobs(4))
+	if err != nil {
+		t.Fatalf("RecoverBatch: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("recovered %d analyses, want 4", len(results))
+	}
+	if len(a.Blocked) != 0 {
+		t.Errorf("blocked jobs = %v, want none for truncated output", a.Blocked)
+	}
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// NewClient returns a Gemini client configured by ClientConfig.
+func NewClient(ctx context.Context) (*genai.Client, error) {
+	config, err := ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := genai.NewClient(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Gemini client: %w", err)
+	}
+	return client, nil
+}
+
+// ClientConfig returns the config of a client using Vertex AI when
+// GOOGLE_GENAI_USE_VERTEXAI is 1 or true, in GOOGLE_CLOUD_PROJECT and
+// GOOGLE_CLOUD_LOCATION with the default Google Cloud credentials, and the
+// Gemini API authenticated with GEMINI_API_KEY otherwise.
+func ClientConfig() (*genai.ClientConfig, error) {
+	useVertex := strings.ToLower(os.Getenv("GOOGLE_GENAI_USE_VERTEXAI"))
+	if useVertex == "1" || useVertex == "true" {
+		project, location := os.Getenv("GOOGLE_CLOUD_PROJECT"), os.Getenv("GOOGLE_CLOUD_LOCATION")
+		if project == "" || location == "" {
+			return nil, errors.New("GOOGLE_CLOUD_PROJECT and GOOGLE_CLOUD_LOCATION must be set to use Vertex AI")
+		}
+		return &genai.ClientConfig{
+			Backend:  genai.BackendVertexAI,
+			Project:  project,
+			Location: location,
+		}, nil
+	}
+
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("GEMINI_API_KEY environment variable not set")
+	}
+	return &genai.ClientConfig{
+		Backend: genai.BackendGeminiAPI,
+		APIKey:  apiKey,
+	}, nil
+}
+
+// ResolveModelName picks the model to use: flagValue, usually a --model
+// flag, wins over the GEMINI_MODEL environment variable, which wins over
+// MODEL_NAME.
+func ResolveModelName(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if envValue := os.Getenv("GEMINI_MODEL"); envValue != "" {
+		return envValue
+	}
+	return MODEL_NAME
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestClientConfig(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "")
+
+	config, err := ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig: %v", err)
+	}
+	if config.Backend != genai.BackendGeminiAPI || config.APIKey != "test-key" {
+		t.Errorf("got backend %v with API key %q, want the Gemini API with test-key", config.Backend, config.APIKey)
+	}
+
+	t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "true")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("GOOGLE_CLOUD_LOCATION", "")
+	if _, err := ClientConfig(); err == nil {
+		t.Error("ClientConfig for Vertex AI succeeded without a project and location")
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+	t.Setenv("GOOGLE_CLOUD_LOCATION", "us-central1")
+	config, err = ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig: %v", err)
+	}
+	if config.Backend != genai.BackendVertexAI || config.Project != "my-project" || config.Location != "us-central1" || config.APIKey != "" {
+		t.Errorf("got config %+v, want Vertex AI in my-project and us-central1", config)
+	}
+}
+
+func TestResolveModelName(t *testing.T) {
+	t.Setenv("GEMINI_MODEL", "")
+	if got := ResolveModelName(""); got != MODEL_NAME {
+		t.Errorf("ResolveModelName with nothing set = %q, want %q", got, MODEL_NAME)
+	}
+
+	t.Setenv("GEMINI_MODEL", "gemini-2.5-flash")
+	if got := ResolveModelName(""); got != "gemini-2.5-flash" {
+		t.Errorf("ResolveModelName with GEMINI_MODEL set = %q, want gemini-2.5-flash", got)
+	}
+	if got := ResolveModelName("gemini-2.5-pro"); got != "gemini-2.5-pro" {
+		t.Errorf("ResolveModelName with a flag = %q, want gemini-2.5-pro", got)
+	}
+}
+
+// modelRecorder is a ContentGenerator recording the model of every request.
+type modelRecorder struct {
+	ContentGenerator
+	models []string
+}
+
+func (r *modelRecorder) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	r.models = append(r.models, model)
+	return r.ContentGenerator.GenerateContent(ctx, model, contents, config)
+}
+
+func TestGeminiModelOverride(t *testing.T) {
+	t.Setenv("GEMINI_MODEL", "gemini-2.5-flash")
+
+	recorder := &modelRecorder{ContentGenerator: &fakeGenerator{respond: analysesResponse}}
+	a := newTestAnalyzer(recorder)
+	a.Model = ResolveModelName("")
+	if _, err := a.ProcessBatch(context.Background(), testJobs(2)); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	if len(recorder.models) != 1 || recorder.models[0] != "gemini-2.5-flash" {
+		t.Errorf("sent requests to models %q, want gemini-2.5-flash", recorder.models)
+	}
+}
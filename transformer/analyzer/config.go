@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/genai"
+)
+
+// AnalysisConfig describes what the model is asked to extract from every job:
+// the system instruction and the fields of each analysis object, from which
+// the response schema is built. It can be loaded from a JSON file with
+// LoadAnalysisConfig to extract other fields without changing the code.
+type AnalysisConfig struct {
+	SystemInstruction string        `json:"system_instruction"`
+	Fields            []FieldConfig `json:"fields"`
+}
+
+// FieldConfig is a single field of the analysis object.
+type FieldConfig struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"` // string, number, integer, boolean, array or object
+	Description string        `json:"description,omitempty"`
+	Enum        []string      `json:"enum,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	Items       *FieldConfig  `json:"items,omitempty"`      // element type of arrays
+	Properties  []FieldConfig `json:"properties,omitempty"` // fields of objects
+}
+
+var schemaTypes = map[string]genai.Type{
+	"string":  genai.TypeString,
+	"number":  genai.TypeNumber,
+	"integer": genai.TypeInteger,
+	"boolean": genai.TypeBoolean,
+	"array":   genai.TypeArray,
+	"object":  genai.TypeObject,
+}
+
+const defaultSystemInstruction = `You are an expert job market analyst. Your task is to extract structured data from the provided job descriptions.
+You MUST return a single JSON array containing an analysis object for every job provided in the input.
+
+IMPORTANT: the answer MUST have EXACTLY ONE object per JobID.
+
+Crucial formatting rules:
+1. Ensure the "job_id" field in the output matches the "Job ID" from the input.
+2. ONLY include technical skills in the skills array.
+3. THIS IS VERY IMPORTANT:  For the skills array, each item MUST be a single, atomic, machine-readable keyword.
+   - DO NOT use full sentences, verbose explanations, or parenthetical remarks.
+   - Example (Good): "gcp", "kubernetes", "data_modeling".
+   - Example (Bad): "Experience with Cloud technologies (AWS/Azure)", "Must have 5+ years of experience in the industry".
+4. You must ONLY use information explicitly present or clearly implied by the job text.
+	**If information for any field other than 'job_id' is NOT found, you MUST omit that field entirely** from the JSON object.
+	For the skills array field, if no items are found, the model must return an **empty array (\[])**.
+	DO NOT make up, infer, or hallucinate any missing data. Keep all array values concise and in lowercase.
+5. In the "confidence" object, rate from 0 to 1 how confident you are in each extracted field and in the analysis overall.
+   Use low values when a field had to be guessed from indirect hints (e.g. seniority without explicit years or title).
+`
+
+// DefaultAnalysisConfig returns the fields that map to JobAnalysis.
+func DefaultAnalysisConfig() *AnalysisConfig {
+	confidence := func(name string) FieldConfig {
+		return FieldConfig{Name: name, Type: "number", Minimum: genai.Ptr(0.0), Maximum: genai.Ptr(1.0)}
+	}
+
+	return &AnalysisConfig{
+		SystemInstruction: defaultSystemInstruction,
+		Fields: []FieldConfig{
+			{
+				Name:        "job_id",
+				Type:        "string",
+				Description: "Job ID, must match the input Job ID.",
+				Required:    true,
+			},
+			{
+				Name:        "seniority",
+				Type:        "string",
+				Description: seniorityDescription(SENIORITY_LEVELS),
+				Enum:        SENIORITY_LEVELS,
+			},
+			{
+				Name:        "skills",
+				Type:        "array",
+				Description: "List of skills for the job.",
+				Items:       &FieldConfig{Type: "string"},
+				Required:    true,
+			},
+			{
+				Name:        "onsite_hybrid_remote",
+				Type:        "string",
+				Description: "The work arrangement for the job.",
+				Enum:        []string{"on_site", "hybrid", "remote"},
+			},
+			{
+				Name:        EXPERIENCE_FIELD,
+				Type:        "array",
+				Description: "The experience the job requires, one item per requirement, keeping the amount of years as written (e.g. \"3+ years with go\", \"tres años en aws\").",
+				Items:       &FieldConfig{Type: "string"},
+			},
+			{
+				Name:        "confidence",
+				Type:        "object",
+				Description: "Confidence from 0 to 1 in the extracted fields.",
+				Properties: []FieldConfig{
+					confidence("overall"),
+					confidence("seniority"),
+					confidence("skills"),
+					confidence("onsite_hybrid_remote"),
+				},
+			},
+		},
+	}
+}
+
+// LoadAnalysisConfig reads an AnalysisConfig from the JSON file at path. A
+// missing system instruction falls back to the default one.
+func LoadAnalysisConfig(path string) (*AnalysisConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read analysis config '%s': %w", path, err)
+	}
+
+	var config AnalysisConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse analysis config '%s': %w", path, err)
+	}
+
+	if config.SystemInstruction == "" {
+		config.SystemInstruction = defaultSystemInstruction
+	}
+
+	if _, err := config.Schema(); err != nil {
+		return nil, fmt.Errorf("invalid analysis config '%s': %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Schema builds the response schema: an array with one object per job.
+func (c *AnalysisConfig) Schema() (*genai.Schema, error) {
+	hasJobID := false
+	for _, field := range c.Fields {
+		if field.Name == "job_id" && field.Type == "string" {
+			hasJobID = true
+		}
+	}
+	if !hasJobID {
+		return nil, fmt.Errorf("a string field named job_id is required")
+	}
+
+	item, err := objectSchema(c.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &genai.Schema{Type: genai.TypeArray, Items: item}, nil
+}
+
+func objectSchema(fields []FieldConfig) (*genai.Schema, error) {
+	schema := &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: make(map[string]*genai.Schema, len(fields)),
+	}
+
+	for _, field := range fields {
+		if field.Name == "" {
+			return nil, fmt.Errorf("object fields must have a name")
+		}
+		if _, ok := schema.Properties[field.Name]; ok {
+			return nil, fmt.Errorf("duplicated field '%s'", field.Name)
+		}
+
+		fieldSchema, err := field.schema()
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", field.Name, err)
+		}
+
+		schema.Properties[field.Name] = fieldSchema
+		if field.Required {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+
+	return schema, nil
+}
+
+func (f FieldConfig) schema() (*genai.Schema, error) {
+	schemaType, ok := schemaTypes[f.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported type '%s'", f.Type)
+	}
+
+	schema := &genai.Schema{
+		Type:        schemaType,
+		Description: f.Description,
+		Enum:        f.Enum,
+		Minimum:     f.Minimum,
+		Maximum:     f.Maximum,
+	}
+
+	switch schemaType {
+	case genai.TypeArray:
+		if f.Items == nil {
+			return nil, fmt.Errorf("arrays must define their items")
+		}
+		items, err := f.Items.schema()
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		schema.Items = items
+
+	case genai.TypeObject:
+		object, err := objectSchema(f.Properties)
+		if err != nil {
+			return nil, err
+		}
+		schema.Properties = object.Properties
+		schema.Required = object.Required
+	}
+
+	return schema, nil
+}
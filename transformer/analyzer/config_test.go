@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestDefaultSchemaConfidence(t *testing.T) {
+	schema, err := DefaultAnalysisConfig().Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+
+	confidence, ok := schema.Items.Properties["confidence"]
+	if !ok || confidence.Type != genai.TypeObject {
+		t.Fatalf("schema has confidence %+v, want an object", confidence)
+	}
+	for _, name := range []string{"overall", "seniority", "skills", "onsite_hybrid_remote"} {
+		property, ok := confidence.Properties[name]
+		if !ok || property.Type != genai.TypeNumber || *property.Minimum != 0 || *property.Maximum != 1 {
+			t.Errorf("confidence has %s %+v, want a number from 0 to 1", name, property)
+		}
+	}
+	if slices.Contains(schema.Items.Required, "confidence") {
+		t.Error("confidence is required, want models that don't report it to be accepted")
+	}
+}
+
+func TestLoadAnalysisConfigExtraField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analysis.json")
+	config := `{
+		"fields": [
+			{"name": "job_id", "type": "string", "required": true},
+			{"name": "seniority", "type": "string", "enum": ["Junior", "Mid", "Senior", "Staff"]},
+			{"name": "skills", "type": "array", "items": {"type": "string"}},
+			{"name": "industry", "type": "string", "description": "Industry of the hiring company."}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadAnalysisConfig(path)
+	if err != nil {
+		t.Fatalf("LoadAnalysisConfig: %v", err)
+	}
+	if loaded.SystemInstruction != defaultSystemInstruction {
+		t.Error("config without a system instruction does not fall back to the default one")
+	}
+
+	schema, err := loaded.Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	industry, ok := schema.Items.Properties["industry"]
+	if !ok || industry.Type != genai.TypeString || industry.Description != "Industry of the hiring company." {
+		t.Errorf("schema has industry %+v, want the configured string field", industry)
+	}
+	if enum := schema.Items.Properties["seniority"].Enum; !slices.Equal(enum, []string{"Junior", "Mid", "Senior", "Staff"}) {
+		t.Errorf("seniority enum = %q, want the configured buckets", enum)
+	}
+	if !slices.Equal(schema.Items.Required, []string{"job_id"}) {
+		t.Errorf("required fields = %q, want only job_id", schema.Items.Required)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"fields": [{"name": "industry", "type": "string"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAnalysisConfig(path); err == nil {
+		t.Error("LoadAnalysisConfig without a job_id field succeeded, want an error")
+	}
+}
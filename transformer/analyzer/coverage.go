@@ -0,0 +1,45 @@
+package analyzer
+
+// CoverageReport compares the jobs sent for analysis with the analyses
+// received, surfacing the jobs lost to skipped batches or model omissions.
+type CoverageReport struct {
+	JobsIn      int `json:"jobs_in"`
+	AnalysesOut int `json:"analyses_out"`
+	// Missing are the input job IDs without an analysis, in input order.
+	Missing []string `json:"missing"`
+	// Unexpected are the analyzed job IDs that were not in the input.
+	Unexpected []string `json:"unexpected,omitempty"`
+	// Blocked are the jobs Gemini refused to analyze because of their
+	// content, by job ID, with the reason.
+	Blocked map[string]string `json:"blocked,omitempty"`
+}
+
+// NewCoverageReport returns the coverage of the analyses with analyzedIDs
+// over jobs.
+func NewCoverageReport(jobs []JobInput, analyzedIDs []string) CoverageReport {
+	inputIDs := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		inputIDs[job.JobID] = true
+	}
+
+	analyzed := make(map[string]bool, len(analyzedIDs))
+	report := CoverageReport{
+		JobsIn:      len(jobs),
+		AnalysesOut: len(analyzedIDs),
+		Missing:     []string{},
+	}
+	for _, id := range analyzedIDs {
+		if !inputIDs[id] && !analyzed[id] {
+			report.Unexpected = append(report.Unexpected, id)
+		}
+		analyzed[id] = true
+	}
+
+	for _, job := range jobs {
+		if !analyzed[job.JobID] {
+			report.Missing = append(report.Missing, job.JobID)
+		}
+	}
+
+	return report
+}
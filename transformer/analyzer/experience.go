@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EXPERIENCE_FIELD is the field of the default AnalysisConfig, and of the
+// custom ones that keep it, listing the experience a job requires, e.g.
+// ["3+ years with Go", "tres años en AWS"], that MinYearsExperience is parsed
+// from.
+const EXPERIENCE_FIELD = "mandatory_experience"
+
+// numberWords are the spelled out numbers accepted in experience phrases, in
+// English and Spanish.
+var numberWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"un": 1, "uno": 1, "una": 1, "dos": 2, "tres": 3, "cuatro": 4, "cinco": 5,
+	"seis": 6, "siete": 7, "ocho": 8, "nueve": 9, "diez": 10,
+}
+
+// yearsPattern matches an amount of years: a number, or a spelled out one,
+// optionally followed by "+" or the upper end of a range ("3-5", "3 a 5",
+// "3 to 5"), then the word for years.
+var yearsPattern = regexp.MustCompile(`(?i)\b(\d+|` + numberWordAlternation() + `)\s*\+?\s*(?:(?:-|–|a|to)\s*\d+\s*\+?\s*)?(?:years?|yrs?|años?|anos?)\b`)
+
+// numberWordAlternation joins numberWords longest first, so a word is never
+// cut short by one of its prefixes ("un" in "uno"), and alphabetically among
+// equally long ones so the pattern is the same on every run.
+func numberWordAlternation() string {
+	words := make([]string, 0, len(numberWords))
+	for word := range numberWords {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i]) != len(words[j]) {
+			return len(words[i]) > len(words[j])
+		}
+		return words[i] < words[j]
+	})
+	return strings.Join(words, "|")
+}
+
+// ParseYears returns the least number of years an experience phrase asks for,
+// e.g. 3 for "3+ years", "3-5 years" or "tres años de experiencia". It
+// reports false when the phrase has no amount of years.
+func ParseYears(phrase string) (int, bool) {
+	match := yearsPattern.FindStringSubmatch(phrase)
+	if match == nil {
+		return 0, false
+	}
+
+	if years, err := strconv.Atoi(match[1]); err == nil {
+		return years, true
+	}
+	years, ok := numberWords[strings.ToLower(match[1])]
+	return years, ok
+}
+
+// minYearsExperience returns the years of experience a job requires
+// according to its EXPERIENCE_FIELD: the most asked for by any of its
+// requirements, since the job needs all of them. It returns nil when none
+// gives a number of years.
+func minYearsExperience(extra map[string]any) *int {
+	var phrases []string
+	switch value := extra[EXPERIENCE_FIELD].(type) {
+	case string:
+		phrases = []string{value}
+	case []any:
+		for _, item := range value {
+			if phrase, ok := item.(string); ok {
+				phrases = append(phrases, phrase)
+			}
+		}
+	}
+
+	var minYears *int
+	for _, phrase := range phrases {
+		if years, ok := ParseYears(phrase); ok && (minYears == nil || years > *minYears) {
+			minYears = &years
+		}
+	}
+	return minYears
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestParseYears(t *testing.T) {
+	tests := []struct {
+		phrase string
+		want   int
+		ok     bool
+	}{
+		{"3 years of experience with Go", 3, true},
+		{"3+ years with Go", 3, true},
+		{"3-5 years in backend development", 3, true},
+		{"3 to 5 yrs", 3, true},
+		{"1 year with AWS", 1, true},
+		{"Five years of Java", 5, true},
+		{"tres años de experiencia", 3, true},
+		{"Al menos 2 años con Python", 2, true},
+		{"3 a 5 años en roles similares", 3, true},
+		{"+4 anos de experiencia", 4, true},
+		{"un año en soporte", 1, true},
+		{"uno años de soporte", 1, true},
+		{"cuatro años con SQL", 4, true},
+		{"10+ YEARS", 10, true},
+		{"experience with Kubernetes", 0, false},
+		{"experiencia comprobable", 0, false},
+		{"Go 1.21", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		got, ok := ParseYears(test.phrase)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ParseYears(%q) = %d, %v; want %d, %v", test.phrase, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestNumberWordAlternationLongestFirst(t *testing.T) {
+	alternation := numberWordAlternation()
+	words := strings.Split(alternation, "|")
+	if len(words) != len(numberWords) {
+		t.Fatalf("got %d words, want %d", len(words), len(numberWords))
+	}
+	for i := 1; i < len(words); i++ {
+		if len(words[i]) > len(words[i-1]) || (len(words[i]) == len(words[i-1]) && words[i] < words[i-1]) {
+			t.Errorf("%q comes before %q, want longest first then alphabetical", words[i-1], words[i])
+		}
+	}
+	if again := numberWordAlternation(); again != alternation {
+		t.Errorf("got %q, then %q", alternation, again)
+	}
+}
+
+func TestMinYearsExperience(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]any
+		want  *int
+	}{
+		{"most asked for", map[string]any{EXPERIENCE_FIELD: []any{"2+ years with Go", "tres años en AWS", "docker"}}, genai.Ptr(3)},
+		{"single phrase", map[string]any{EXPERIENCE_FIELD: "5-7 years"}, genai.Ptr(5)},
+		{"no years", map[string]any{EXPERIENCE_FIELD: []any{"docker", "kubernetes"}}, nil},
+		{"no field", nil, nil},
+	}
+
+	for _, test := range tests {
+		got := minYearsExperience(test.extra)
+		if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, formatYears(got), formatYears(test.want))
+		}
+	}
+}
+
+func formatYears(years *int) any {
+	if years == nil {
+		return nil
+	}
+	return *years
+}
+
+func TestProcessBatchParsesMinYearsExperience(t *testing.T) {
+	hasField := false
+	for _, field := range DefaultAnalysisConfig().Fields {
+		hasField = hasField || field.Name == EXPERIENCE_FIELD
+	}
+	if !hasField {
+		t.Fatalf("the default analysis config does not ask for %s", EXPERIENCE_FIELD)
+	}
+
+	generator := &fakeGenerator{respond: func([]string) (*genai.GenerateContentResponse, error) {
+		return textResponse(`[
+			{"job_id": "job-01", "skills": [], "mandatory_experience": ["3+ años con Go", "5 years of SQL"]},
+			{"job_id": "job-02", "skills": [], "mandatory_experience": ["solid Go experience"]}
+		]`), nil
+	}}
+	a := newTestAnalyzer(generator)
+
+	results, err := a.ProcessBatch(context.Background(), testJobs(2))
+	if err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if years := results[0].MinYearsExperience; years == nil || *years != 5 {
+		t.Errorf("min years of job-01 = %v, want 5", formatYears(years))
+	}
+	if years := results[1].MinYearsExperience; years != nil {
+		t.Errorf("min years of job-02 = %d, want none", *years)
+	}
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff computes the delay before retrying a failed API call using
+// exponential backoff with full jitter: the delay is random between zero and
+// Base * 2^attempt, capped at Cap. The jitter keeps concurrent batches that
+// failed together from retrying in lockstep.
+type Backoff struct {
+	Base time.Duration
+	Cap  time.Duration
+
+	// Rand is the source of the jitter. Tests can seed it to get
+	// reproducible delays.
+	Rand *rand.Rand
+	// After returns a channel receiving once the computed delay passed.
+	// Tests can replace it with a fake clock.
+	After func(time.Duration) <-chan time.Time
+
+	// mu guards Rand, which is not safe for concurrent use.
+	mu sync.Mutex
+}
+
+// NewBackoff returns the default backoff between Gemini API attempts.
+func NewBackoff() *Backoff {
+	return &Backoff{
+		Base:  time.Second,
+		Cap:   30 * time.Second,
+		Rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		After: time.After,
+	}
+}
+
+// Delay returns the jittered delay before retry number attempt (0 based).
+func (b *Backoff) Delay(attempt int) time.Duration {
+	ceiling := b.Cap
+	if attempt < 62 {
+		if d := b.Base << attempt; d > 0 && d < b.Cap {
+			ceiling = d
+		}
+	}
+
+	if ceiling <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Duration(b.Rand.Int63n(int64(ceiling) + 1))
+}
+
+// wait waits for d before a retry, unless ctx is done or its deadline comes
+// first, in which case it returns right away with the context's error.
+func (b *Backoff) wait(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		// Sleeping past the deadline would only delay the abort
+		return context.DeadlineExceeded
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case <-b.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
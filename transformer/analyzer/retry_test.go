@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestBackoffWaitStopsWhenContextIsDone(t *testing.T) {
+	b := NewBackoff()
+	b.After = func(time.Duration) <-chan time.Time { return nil } // never fires
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := b.wait(ctx, 30*time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait returned %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("wait returned %v after the context was canceled", elapsed)
+	}
+}
+
+func TestBackoffDelayWithinJitterBounds(t *testing.T) {
+	b := NewBackoff()
+	b.Base = 100 * time.Millisecond
+	b.Cap = 300 * time.Millisecond
+	b.Rand = rand.New(rand.NewSource(1))
+
+	ms := time.Millisecond
+	for attempt, ceiling := range []time.Duration{100 * ms, 200 * ms, 300 * ms, 300 * ms, 300 * ms} {
+		for range 100 {
+			if d := b.Delay(attempt); d < 0 || d > ceiling {
+				t.Fatalf("Delay(%d) = %v, want between 0 and %v", attempt, d, ceiling)
+			}
+		}
+	}
+}
+
+func TestProcessBatchRetriesWithinJitterBounds(t *testing.T) {
+	generator := &fakeGenerator{}
+	generator.respond = func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		if generator.callCount() < 3 {
+			return nil, errors.New("service unavailable")
+		}
+		return analysesResponse(jobIDs)
+	}
+	a := newTestAnalyzer(generator)
+	a.Backoff.Base = time.Second
+	a.Backoff.Cap = 30 * time.Second
+	a.Backoff.Rand = rand.New(rand.NewSource(1))
+
+	// The fake clock records every wait and lets it pass right away
+	var waits []time.Duration
+	a.Backoff.After = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		return time.After(0)
+	}
+
+	if _, err := a.ProcessBatch(context.Background(), testJobs(1)); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(waits) != 2 {
+		t.Fatalf("waited %d times, want once before each of the 2 retries", len(waits))
+	}
+	for attempt, d := range waits {
+		if ceiling := time.Second << attempt; d < 0 || d > ceiling {
+			t.Errorf("retry %d waited %v, want between 0 and %v", attempt+1, d, ceiling)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SENIORITY_LEVELS are the seniorities of the default analysis config, from
+// least to most senior.
+var SENIORITY_LEVELS = []string{"Junior", "Semisenior", "Senior", "Lead"}
+
+// seniorityAliases maps other ways the model may name a seniority, collapsed
+// like skills, to the level they stand for.
+var seniorityAliases = map[string]string{
+	"jr":                  "Junior",
+	"trainee":             "Junior",
+	"entry level":         "Junior",
+	"ssr":                 "Semisenior",
+	"semi senior":         "Semisenior",
+	"semi-senior":         "Semisenior",
+	"mid":                 "Semisenior",
+	"mid level":           "Semisenior",
+	"mid-level":           "Semisenior",
+	"sr":                  "Senior",
+	"staff":               "Lead",
+	"principal":           "Lead",
+	"tech lead":           "Lead",
+	"manager":             "Lead",
+	"engineering manager": "Lead",
+}
+
+// seniorityDescription describes the seniority field restricted to levels.
+func seniorityDescription(levels []string) string {
+	description := "The seniority level of the job."
+	for _, level := range levels {
+		if level == "Lead" {
+			description += " Lead covers tech lead, staff, principal and engineering manager roles."
+		}
+	}
+	return description
+}
+
+// SeniorityLevels returns the values allowed for the seniority field, or nil
+// when the config has no seniority field or doesn't restrict it.
+func (c *AnalysisConfig) SeniorityLevels() []string {
+	for _, field := range c.Fields {
+		if field.Name == "seniority" {
+			return field.Enum
+		}
+	}
+	return nil
+}
+
+// SetSeniorityLevels restricts the seniority field to levels, e.g. to use a
+// taxonomy other than SENIORITY_LEVELS.
+func (c *AnalysisConfig) SetSeniorityLevels(levels []string) error {
+	if len(levels) == 0 {
+		return fmt.Errorf("at least one seniority level is required")
+	}
+
+	for i, field := range c.Fields {
+		if field.Name == "seniority" && field.Type == "string" {
+			c.Fields[i].Enum = levels
+			c.Fields[i].Description = seniorityDescription(levels)
+			return nil
+		}
+	}
+	return fmt.Errorf("the analysis config has no string field named seniority")
+}
+
+// normalizeSeniority returns the level of levels seniority names, ignoring
+// case and through seniorityAliases, or "" when it names none of them and
+// the seniority is left unknown. Without levels any value is kept.
+func normalizeSeniority(seniority string, levels []string) string {
+	if len(levels) == 0 || seniority == "" {
+		return seniority
+	}
+
+	collapsed := collapseSkill(seniority)
+	for _, name := range []string{collapsed, strings.ToLower(seniorityAliases[collapsed])} {
+		for _, level := range levels {
+			if name != "" && collapseSkill(level) == name {
+				return level
+			}
+		}
+	}
+	return ""
+}
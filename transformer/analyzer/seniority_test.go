@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSeniority(t *testing.T) {
+	tests := []struct {
+		seniority string
+		want      string
+	}{
+		{"Lead", "Lead"},
+		{"lead", "Lead"},
+		{"Tech Lead", "Lead"},
+		{"Staff", "Lead"},
+		{"principal", "Lead"},
+		{"Engineering  Manager", "Lead"},
+		{"Sr", "Senior"},
+		{"semi-senior", "Semisenior"},
+		{"Trainee", "Junior"},
+		{"Director", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSeniority(tt.seniority, SENIORITY_LEVELS); got != tt.want {
+			t.Errorf("normalizeSeniority(%q) = %q, want %q", tt.seniority, got, tt.want)
+		}
+	}
+
+	if got := normalizeSeniority("Tech Lead", nil); got != "Tech Lead" {
+		t.Errorf("got %q without levels, want the value kept", got)
+	}
+}
+
+func TestSetSeniorityLevelsDescribesLead(t *testing.T) {
+	config := DefaultAnalysisConfig()
+	if err := config.SetSeniorityLevels(SENIORITY_LEVELS); err != nil {
+		t.Fatalf("SetSeniorityLevels: %v", err)
+	}
+
+	for _, field := range config.Fields {
+		if field.Name == "seniority" && !strings.Contains(field.Description, "Lead covers tech lead") {
+			t.Errorf("seniority is described as %q, want the Lead bucket explained", field.Description)
+		}
+	}
+}
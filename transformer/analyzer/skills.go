@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultSkillAliases returns the built-in map from alternative spellings of a
+// skill, after normalization, to its canonical name.
+func DefaultSkillAliases() map[string]string {
+	return map[string]string{
+		"k8s":                   "kubernetes",
+		"postgres":              "postgresql",
+		"golang":                "go",
+		"js":                    "javascript",
+		"ts":                    "typescript",
+		"amazon web services":   "aws",
+		"google cloud":          "gcp",
+		"google cloud platform": "gcp",
+		"ml":                    "machine learning",
+		"ci cd":                 "ci/cd",
+		"scikit learn":          "scikit-learn",
+		"sklearn":               "scikit-learn",
+	}
+}
+
+// NormalizeSkill trims, lowercases and collapses the internal whitespace of a
+// skill and resolves it through aliases, so the same skill is always spelled
+// the same way regardless of how the model wrote it.
+func NormalizeSkill(skill string, aliases map[string]string) string {
+	skill = collapseSkill(skill)
+	if canonical, ok := aliases[skill]; ok {
+		return canonical
+	}
+	return skill
+}
+
+// collapseSkill lowercases skill and collapses its whitespace.
+func collapseSkill(skill string) string {
+	return strings.Join(strings.Fields(strings.ToLower(skill)), " ")
+}
+
+// MANDATORY_SKILLS_FIELD and NICE_TO_HAVE_SKILLS_FIELD are the fields of
+// custom AnalysisConfigs that split the skills of a job by whether they are
+// required, and NICE_TO_HAVE_EXPERIENCE_FIELD the counterpart of
+// EXPERIENCE_FIELD. An item in both fields of a pair is only kept as
+// mandatory.
+const (
+	MANDATORY_SKILLS_FIELD        = "mandatory_skills"
+	NICE_TO_HAVE_SKILLS_FIELD     = "nice_to_have_skills"
+	NICE_TO_HAVE_EXPERIENCE_FIELD = "nice_to_have_experience"
+)
+
+// mandatoryFields maps every nice to have field to its mandatory counterpart.
+var mandatoryFields = map[string]string{
+	NICE_TO_HAVE_SKILLS_FIELD:     MANDATORY_SKILLS_FIELD,
+	NICE_TO_HAVE_EXPERIENCE_FIELD: EXPERIENCE_FIELD,
+}
+
+// normalizeAnalysis normalizes every skill of analysis, dropping the ones left
+// empty and the repeated ones. Skills keep the order the model listed them in.
+// The string arrays of custom fields in Extra are cleaned up the same way,
+// resolving aliases only in the ones named like *skills.
+func normalizeAnalysis(analysis *JobAnalysis, aliases map[string]string) {
+	normalizeSkill := func(skill string) string {
+		return NormalizeSkill(skill, aliases)
+	}
+	normalizerOf := func(name string) func(string) string {
+		if strings.HasSuffix(name, "skills") {
+			return normalizeSkill
+		}
+		return collapseSkill
+	}
+
+	analysis.Skills = dedupeStrings(analysis.Skills, normalizeSkill, nil)
+
+	for name, value := range analysis.Extra {
+		items, ok := stringArray(value)
+		if _, niceToHave := mandatoryFields[name]; !ok || niceToHave {
+			continue
+		}
+		analysis.Extra[name] = anyArray(dedupeStrings(items, normalizerOf(name), nil))
+	}
+
+	// Normalized last so the mandatory items they are checked against are too
+	for name, mandatoryName := range mandatoryFields {
+		items, ok := stringArray(analysis.Extra[name])
+		if !ok {
+			continue
+		}
+
+		mandatory, _ := stringArray(analysis.Extra[mandatoryName])
+		exclude := make(map[string]bool, len(mandatory))
+		for _, item := range mandatory {
+			exclude[item] = true
+		}
+		analysis.Extra[name] = anyArray(dedupeStrings(items, normalizerOf(name), exclude))
+	}
+}
+
+// dedupeStrings normalizes items in place, dropping the ones left empty, the
+// repeated ones and the ones in exclude.
+func dedupeStrings(items []string, normalize func(string) string, exclude map[string]bool) []string {
+	seen := make(map[string]bool, len(items))
+	deduped := items[:0]
+	for _, item := range items {
+		item = normalize(item)
+		if item == "" || seen[item] || exclude[item] {
+			continue
+		}
+		seen[item] = true
+		deduped = append(deduped, item)
+	}
+	return deduped
+}
+
+// stringArray returns the items of value when it is a JSON array of strings,
+// as decoded into Extra.
+func stringArray(value any) ([]string, bool) {
+	array, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+
+	items := make([]string, len(array))
+	for i, item := range array {
+		if items[i], ok = item.(string); !ok {
+			return nil, false
+		}
+	}
+	return items, true
+}
+
+// anyArray converts items back into the []any Extra holds decoded arrays as.
+func anyArray(items []string) []any {
+	array := make([]any, len(items))
+	for i, item := range items {
+		array[i] = item
+	}
+	return array
+}
+
+// LoadSkillAliases adds the aliases in the JSON object at path (alias to
+// canonical name) to aliases, overriding the ones already there.
+func LoadSkillAliases(path string, aliases map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read skill aliases file '%s': %w", path, err)
+	}
+
+	var loaded map[string]string
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("could not parse skill aliases file '%s': %w", path, err)
+	}
+
+	for alias, canonical := range loaded {
+		aliases[collapseSkill(alias)] = collapseSkill(canonical)
+	}
+
+	return nil
+}
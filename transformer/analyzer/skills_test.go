@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeAnalysis(t *testing.T) {
+	var analysis JobAnalysis
+	err := json.Unmarshal([]byte(`{
+		"job_id": "1",
+		"skills": ["Golang", "go", " Go ", "K8s", "", "Kubernetes", "AWS"],
+		"mandatory_skills": ["Golang", "PostgreSQL", "postgres", " AWS "],
+		"nice_to_have_skills": ["go", "Terraform", "terraform", "amazon web services", "k8s"],
+		"mandatory_experience": ["3+ years with Go", "3+  YEARS with go", "tres años en AWS"],
+		"nice_to_have_experience": ["Tres años en AWS", "Startups", "startups"],
+		"benefits": [{"name": "remote"}, {"name": "remote"}],
+		"notes": "Golang  Golang"
+	}`), &analysis)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	normalizeAnalysis(&analysis, DefaultSkillAliases())
+
+	if want := []string{"go", "kubernetes", "aws"}; !slices.Equal(analysis.Skills, want) {
+		t.Errorf("skills = %q, want %q", analysis.Skills, want)
+	}
+
+	want := map[string]any{
+		"mandatory_skills":        []any{"go", "postgresql", "aws"},
+		"nice_to_have_skills":     []any{"terraform", "kubernetes"},
+		"mandatory_experience":    []any{"3+ years with go", "tres años en aws"},
+		"nice_to_have_experience": []any{"startups"},
+		// Left alone: not arrays of strings
+		"benefits": []any{map[string]any{"name": "remote"}, map[string]any{"name": "remote"}},
+		"notes":    "Golang  Golang",
+	}
+	if !reflect.DeepEqual(analysis.Extra, want) {
+		t.Errorf("extra fields = %#v, want %#v", analysis.Extra, want)
+	}
+}
+
+func TestNormalizeSkill(t *testing.T) {
+	aliases := DefaultSkillAliases()
+	tests := map[string]string{
+		"  Google   Cloud ": "gcp",
+		"CI CD":             "ci/cd",
+		"Rust":              "rust",
+		"   ":               "",
+	}
+
+	for skill, want := range tests {
+		if got := NormalizeSkill(skill, aliases); got != want {
+			t.Errorf("NormalizeSkill(%q) = %q, want %q", skill, got, want)
+		}
+	}
+}
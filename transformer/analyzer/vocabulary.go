@@ -0,0 +1,53 @@
+package analyzer
+
+import "sort"
+
+// SkillCount is the number of analyses listing a skill.
+type SkillCount struct {
+	Skill string `json:"skill"`
+	Count int    `json:"count"`
+}
+
+// Vocabulary counts the distinct skills across analyses, normalized through
+// the skill aliases so every skill is counted under its canonical name.
+type Vocabulary struct {
+	aliases map[string]string
+	counts  map[string]int
+}
+
+// NewVocabulary returns an empty Vocabulary normalizing skills with aliases.
+func NewVocabulary(aliases map[string]string) *Vocabulary {
+	return &Vocabulary{aliases: aliases, counts: make(map[string]int)}
+}
+
+// Add counts the skills of analyses, once per analysis.
+func (v *Vocabulary) Add(analyses []JobAnalysis) {
+	for _, analysis := range analyses {
+		seen := make(map[string]bool, len(analysis.Skills))
+		for _, skill := range analysis.Skills {
+			skill = NormalizeSkill(skill, v.aliases)
+			if skill == "" || seen[skill] {
+				continue
+			}
+			seen[skill] = true
+			v.counts[skill]++
+		}
+	}
+}
+
+// Skills returns every skill with its count, most frequent first and
+// alphabetically among equally frequent ones.
+func (v *Vocabulary) Skills() []SkillCount {
+	skills := make([]SkillCount, 0, len(v.counts))
+	for skill, count := range v.counts {
+		skills = append(skills, SkillCount{Skill: skill, Count: count})
+	}
+
+	sort.Slice(skills, func(i, j int) bool {
+		if skills[i].Count != skills[j].Count {
+			return skills[i].Count > skills[j].Count
+		}
+		return skills[i].Skill < skills[j].Skill
+	})
+	return skills
+}
@@ -0,0 +1,27 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVocabulary(t *testing.T) {
+	vocabulary := NewVocabulary(map[string]string{"golang": "go", "k8s": "kubernetes"})
+	vocabulary.Add([]JobAnalysis{
+		{Skills: []string{"Go", "Golang", "SQL"}},
+		{Skills: []string{"k8s", " go ", ""}},
+	})
+	vocabulary.Add([]JobAnalysis{
+		{Skills: []string{"Kubernetes", "Docker"}},
+	})
+
+	want := []SkillCount{
+		{Skill: "go", Count: 2},
+		{Skill: "kubernetes", Count: 2},
+		{Skill: "docker", Count: 1},
+		{Skill: "sql", Count: 1},
+	}
+	if got := vocabulary.Skills(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
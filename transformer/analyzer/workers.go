@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// BatchResult is the outcome of RecoverBatch for the batch at Index.
+type BatchResult struct {
+	Index    int
+	Batch    []JobInput
+	Analyses []JobAnalysis
+	Err      error
+}
+
+// ProcessBatches runs RecoverBatch on batches with up to workers of them in
+// flight and calls handle with the result of every batch in batch order, from
+// the calling goroutine. Returning false from handle stops the run: no more
+// batches are started and the ones in flight are canceled.
+//
+// When ctx is canceled or reaches its deadline, the batches in flight are
+// cut short and the results of every batch that finished, fully or partly,
+// are still handled before returning.
+//
+// Every goroutine ProcessBatches starts has returned by the time it returns,
+// even when handle stops the run or ctx is canceled, so none outlives the
+// caller. It returns the number of batches handled.
+func (a *Analyzer) ProcessBatches(ctx context.Context, batches [][]JobInput, workers int, handle func(BatchResult) bool) int {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each batch gets its own buffered channel, so workers never block on
+	// sending a result handle isn't ready for yet.
+	results := make([]chan BatchResult, len(batches))
+	for i := range results {
+		results[i] = make(chan BatchResult, 1)
+	}
+
+	inFlight := make(chan struct{}, workers)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, batch := range batches {
+			select {
+			case inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			wg.Add(1)
+			go func(i int, batch []JobInput) {
+				defer wg.Done()
+				defer func() { <-inFlight }()
+
+				log.Printf("[batch %s] Processing batch %d/%d (containing %d jobs)...\n", BatchID(batch), i+1, len(batches), len(batch))
+				analyses, err := a.RecoverBatch(ctx, batch)
+				results[i] <- BatchResult{Index: i, Batch: batch, Analyses: analyses, Err: err}
+			}(i, batch)
+		}
+	}()
+
+	handled := 0
+	for i := range batches {
+		var result BatchResult
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			// Once the workers return, the batches that never started are
+			// the only ones without a result
+			wg.Wait()
+			for _, finished := range results[i:] {
+				select {
+				case result := <-finished:
+					handled++
+					if !handle(result) {
+						return handled
+					}
+				default:
+				}
+			}
+			return handled
+		}
+
+		handled++
+		if !handle(result) {
+			return handled
+		}
+	}
+	return handled
+}
@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// checkGoroutineLeaks fails t if more goroutines are running when it ends
+// than when checkGoroutineLeaks was called. Goroutines finishing their
+// deferred calls get a moment to exit.
+func checkGoroutineLeaks(t *testing.T) {
+	t.Helper()
+
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if after := runtime.NumGoroutine(); after > before {
+			buf := make([]byte, 1<<16)
+			t.Errorf("%d goroutines leaked:\n%s", after-before, buf[:runtime.Stack(buf, true)])
+		}
+	})
+}
+
+// concurrencyGenerator answers like analysesResponse after a short delay,
+// recording the most requests it had in flight at once.
+func concurrencyGenerator() (*fakeGenerator, *atomic.Int32) {
+	var inFlight, maxInFlight atomic.Int32
+	generator := &fakeGenerator{respond: func(jobIDs []string) (*genai.GenerateContentResponse, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		return analysesResponse(jobIDs)
+	}}
+	return generator, &maxInFlight
+}
+
+// splitBatches returns jobs in batches of size.
+func splitBatches(jobs []JobInput, size int) [][]JobInput {
+	var batches [][]JobInput
+	for len(jobs) > 0 {
+		n := min(size, len(jobs))
+		batches = append(batches, jobs[:n])
+		jobs = jobs[n:]
+	}
+	return batches
+}
+
+func TestProcessBatchesWorkers(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	generator, maxInFlight := concurrencyGenerator()
+	a := newTestAnalyzer(generator)
+	batches := splitBatches(testJobs(12), 2)
+
+	var order []int
+	analyzed := 0
+	handled := a.ProcessBatches(context.Background(), batches, 2, func(result BatchResult) bool {
+		if result.Err != nil {
+			t.Errorf("batch %d failed: %v", result.Index, result.Err)
+		}
+		order = append(order, result.Index)
+		analyzed += len(result.Analyses)
+		return true
+	})
+
+	if handled != len(batches) || analyzed != 12 {
+		t.Errorf("handled %d batches with %d analyses, want %d with 12", handled, analyzed, len(batches))
+	}
+	for i, index := range order {
+		if index != i {
+			t.Fatalf("handled batches in order %v, want batch order", order)
+		}
+	}
+	if peak := maxInFlight.Load(); peak > 2 {
+		t.Errorf("had %d requests in flight, want at most 2", peak)
+	}
+}
+
+func TestProcessBatchesStopsOnHandle(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	generator, _ := concurrencyGenerator()
+	a := newTestAnalyzer(generator)
+	batches := splitBatches(testJobs(12), 2)
+
+	handled := a.ProcessBatches(context.Background(), batches, 2, func(result BatchResult) bool {
+		return result.Index < 1
+	})
+
+	if handled != 2 {
+		t.Errorf("handled %d batches, want 2", handled)
+	}
+	if calls := generator.callCount(); calls >= len(batches) {
+		t.Errorf("sent %d requests, want the run to stop before sending every batch", calls)
+	}
+}
+
+func TestProcessBatchesCanceled(t *testing.T) {
+	checkGoroutineLeaks(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	generator, _ := concurrencyGenerator()
+	a := newTestAnalyzer(generator)
+	batches := splitBatches(testJobs(12), 2)
+
+	handled := a.ProcessBatches(ctx, batches, 2, func(result BatchResult) bool {
+		if result.Index == 0 {
+			cancel()
+		}
+		return true
+	})
+
+	// Which batches in flight finish before noticing is up to the
+	// scheduler, but the one that canceled the run was handled
+	if handled == 0 {
+		t.Error("handled no batches, want the ones finished before canceling")
+	}
+}
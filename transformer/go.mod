@@ -5,6 +5,7 @@ go 1.25.1
 require (
 	google.golang.org/api v0.252.0
 	google.golang.org/genai v1.31.0
+	linkedinScraper v0.0.0
 )
 
 require (
@@ -30,7 +31,10 @@ require (
 	golang.org/x/oauth2 v0.31.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
+	golang.org/x/time v0.14.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251002232023-7c0ddcbb5797 // indirect
 	google.golang.org/grpc v1.75.1 // indirect
 	google.golang.org/protobuf v1.36.10 // indirect
 )
+
+replace linkedinScraper => ../scraper
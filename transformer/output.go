@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"transformer/analyzer"
+)
+
+// ResultWriter receives the analyses of every batch as soon as the batch is
+// processed, and writes them out in a given format.
+type ResultWriter interface {
+	WriteBatch(results []analyzer.JobAnalysis) error
+	Close() error
+}
+
+// newResultWriter returns the ResultWriter for format ("json", "ndjson" or
+// "table") writing to w. compact drops the indentation of the json format.
+func newResultWriter(format string, w io.Writer, compact bool) (ResultWriter, error) {
+	switch format {
+	case "json":
+		return &jsonResultWriter{w: w, compact: compact}, nil
+	case "ndjson":
+		return &ndjsonResultWriter{w: bufio.NewWriter(w)}, nil
+	case "table":
+		return &tableResultWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format '%s': must be json, ndjson or table", format)
+	}
+}
+
+// jsonResultWriter collects every analysis and writes them as a single
+// JSON array on Close, indented unless compact is set.
+type jsonResultWriter struct {
+	w       io.Writer
+	compact bool
+	results []analyzer.JobAnalysis
+}
+
+func (jw *jsonResultWriter) WriteBatch(results []analyzer.JobAnalysis) error {
+	jw.results = append(jw.results, results...)
+	return nil
+}
+
+func (jw *jsonResultWriter) Close() error {
+	var finalJSON []byte
+	var err error
+	if jw.compact {
+		finalJSON, err = json.Marshal(jw.results)
+	} else {
+		finalJSON, err = json.MarshalIndent(jw.results, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("could not marshal final results: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(jw.w, string(finalJSON)); err != nil {
+		return fmt.Errorf("could not write final results: %w", err)
+	}
+	return nil
+}
+
+// ndjsonResultWriter writes one JSON object per line and flushes after every
+// batch, so results are available while the run is still going and are not
+// kept in memory.
+type ndjsonResultWriter struct {
+	w *bufio.Writer
+}
+
+func (nw *ndjsonResultWriter) WriteBatch(results []analyzer.JobAnalysis) error {
+	enc := json.NewEncoder(nw.w)
+	for _, result := range results {
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("could not write result for job %s: %w", result.JobID, err)
+		}
+	}
+
+	if err := nw.w.Flush(); err != nil {
+		return fmt.Errorf("could not flush results: %w", err)
+	}
+	return nil
+}
+
+func (nw *ndjsonResultWriter) Close() error {
+	return nw.w.Flush()
+}
+
+// Widths, in characters, past which table cells are truncated.
+const (
+	tableJobIDWidth  = 12
+	tableFieldWidth  = 14
+	tableSkillsWidth = 40
+	tableTopSkills   = 3
+)
+
+// tableResultWriter collects every analysis and writes them on Close as an
+// aligned table meant for reading in a terminal.
+type tableResultWriter struct {
+	w       io.Writer
+	results []analyzer.JobAnalysis
+}
+
+func (tw *tableResultWriter) WriteBatch(results []analyzer.JobAnalysis) error {
+	tw.results = append(tw.results, results...)
+	return nil
+}
+
+func (tw *tableResultWriter) Close() error {
+	// tabwriter measures cells in runes, so accented text stays aligned
+	w := tabwriter.NewWriter(tw.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB_ID\tSENIORITY\tARRANGEMENT\tTOP SKILLS")
+	for _, r := range tw.results {
+		skills := r.Skills
+		if len(skills) > tableTopSkills {
+			skills = skills[:tableTopSkills]
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			truncate(r.JobID, tableJobIDWidth),
+			truncate(r.Seniority, tableFieldWidth),
+			truncate(r.OnsiteHybridRemote, tableFieldWidth),
+			truncate(strings.Join(skills, ", "), tableSkillsWidth))
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("could not write final results: %w", err)
+	}
+	return nil
+}
+
+// truncate shortens s to at most width characters, ending in "…" when cut.
+// Tabs and newlines are replaced so they can't break the table layout.
+func truncate(s string, width int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// atomicFile writes to a temporary file next to path that replaces path on
+// commit, so readers never see a partially written output. The temporary file
+// is only created on the first write.
+type atomicFile struct {
+	path string
+	f    *os.File
+}
+
+func newAtomicFile(path string) (*atomicFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create directory of '%s': %w", path, err)
+	}
+	return &atomicFile{path: path}, nil
+}
+
+func (af *atomicFile) Write(p []byte) (int, error) {
+	if af.f == nil {
+		f, err := os.CreateTemp(filepath.Dir(af.path), "."+filepath.Base(af.path)+".*.tmp")
+		if err != nil {
+			return 0, fmt.Errorf("could not create temporary file for '%s': %w", af.path, err)
+		}
+		af.f = f
+	}
+	return af.f.Write(p)
+}
+
+// commit moves everything written so far to path.
+func (af *atomicFile) commit() error {
+	if af.f == nil {
+		if _, err := af.Write(nil); err != nil {
+			return err
+		}
+	}
+
+	if err := af.f.Close(); err != nil {
+		os.Remove(af.f.Name())
+		return fmt.Errorf("could not write '%s': %w", af.path, err)
+	}
+	if err := os.Rename(af.f.Name(), af.path); err != nil {
+		os.Remove(af.f.Name())
+		return fmt.Errorf("could not write '%s': %w", af.path, err)
+	}
+	return nil
+}
+
+// abort discards everything written, leaving path untouched. It does nothing
+// on a nil *atomicFile.
+func (af *atomicFile) abort() {
+	if af == nil || af.f == nil {
+		return
+	}
+	af.f.Close()
+	os.Remove(af.f.Name())
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"transformer/analyzer"
+)
+
+func TestJSONResultWriterCompact(t *testing.T) {
+	batches := [][]analyzer.JobAnalysis{
+		{
+			{JobID: "1", Seniority: "Senior", Skills: []string{"go", "sql"}, OnsiteHybridRemote: "remote"},
+			{JobID: "2", Seniority: "Junior", Skills: []string{}, Extra: map[string]any{"benefits": "stock\noptions"}},
+		},
+		{
+			{JobID: "3", Skills: []string{"python"}, Confidence: analyzer.Confidence{Overall: 0.5}},
+		},
+	}
+
+	for _, compact := range []bool{false, true} {
+		var buf bytes.Buffer
+		output, err := newResultWriter("json", &buf, compact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, batch := range batches {
+			if err := output.WriteBatch(batch); err != nil {
+				t.Fatalf("WriteBatch: %v", err)
+			}
+		}
+		if err := output.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		lines := bytes.Count(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+		if compact && lines != 0 {
+			t.Errorf("compact output has %d newlines between objects", lines)
+		}
+		if !compact && lines == 0 {
+			t.Error("default output is not indented")
+		}
+
+		var decoded []analyzer.JobAnalysis
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("could not decode output (compact %v): %v", compact, err)
+		}
+		if want := append(batches[0], batches[1]...); !reflect.DeepEqual(decoded, want) {
+			t.Errorf("output (compact %v) decodes to %+v, want %+v", compact, decoded, want)
+		}
+	}
+}
+
+func TestNDJSONResultWriter(t *testing.T) {
+	batches := [][]analyzer.JobAnalysis{
+		{
+			{JobID: "1", Seniority: "Senior", Skills: []string{"go", "sql"}},
+			{JobID: "2", Skills: []string{}, Extra: map[string]any{"benefits": "stock\noptions"}},
+		},
+		{
+			{JobID: "3", Skills: []string{"python"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	output, err := newResultWriter("ndjson", &buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := 0
+	for _, batch := range batches {
+		if err := output.WriteBatch(batch); err != nil {
+			t.Fatalf("WriteBatch: %v", err)
+		}
+		written += len(batch)
+		if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != written {
+			t.Errorf("output has %d lines after writing %d analyses, want every batch flushed", lines, written)
+		}
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	var decoded []analyzer.JobAnalysis
+	for _, line := range bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n")) {
+		var analysis analyzer.JobAnalysis
+		if err := json.Unmarshal(line, &analysis); err != nil {
+			t.Fatalf("could not decode line %q: %v", line, err)
+		}
+		decoded = append(decoded, analysis)
+	}
+	if want := append(batches[0], batches[1]...); !reflect.DeepEqual(decoded, want) {
+		t.Errorf("output decodes to %+v, want %+v", decoded, want)
+	}
+}
+
+func TestTableResultWriter(t *testing.T) {
+	batches := [][]analyzer.JobAnalysis{
+		{
+			{JobID: "4012345678", Seniority: "Senior", OnsiteHybridRemote: "remote", Skills: []string{"go", "sql", "docker", "kubernetes"}},
+			{JobID: "2", Seniority: "Semi Señor", OnsiteHybridRemote: "hybrid", Skills: []string{"amazon web services", "google cloud platform", "terraform"}},
+		},
+		{
+			{JobID: "401234567890123", Seniority: "Junior", OnsiteHybridRemote: "on\tsite", Skills: []string{}},
+		},
+	}
+
+	var buf bytes.Buffer
+	output, err := newResultWriter("table", &buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, batch := range batches {
+		if err := output.WriteBatch(batch); err != nil {
+			t.Fatalf("WriteBatch: %v", err)
+		}
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("testdata", "table.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("got table\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOutputFileMatchesStdout(t *testing.T) {
+	batch := []analyzer.JobAnalysis{
+		{JobID: "1", Seniority: "Senior", Skills: []string{"go", "sql"}, OnsiteHybridRemote: "remote"},
+		{JobID: "2", Skills: []string{"python"}},
+	}
+
+	for _, format := range []string{"json", "ndjson", "table"} {
+		var stdout bytes.Buffer
+		output, err := newResultWriter(format, &stdout, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := output.WriteBatch(batch); err != nil {
+			t.Fatalf("WriteBatch: %v", err)
+		}
+		if err := output.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		path := filepath.Join(t.TempDir(), "results", "analyses."+format)
+		file, err := newAtomicFile(path)
+		if err != nil {
+			t.Fatalf("newAtomicFile: %v", err)
+		}
+		output, err = newResultWriter(format, file, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := output.WriteBatch(batch); err != nil {
+			t.Fatalf("WriteBatch: %v", err)
+		}
+		if err := output.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("--format %s: %s exists before committing: %v", format, path, err)
+		}
+		if err := file.commit(); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != stdout.String() {
+			t.Errorf("--format %s: file holds\n%s\nstdout got\n%s", format, data, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"linkedinScraper/linkedin"
+	"transformer/analyzer"
+)
+
+// readJobsFromRawDir reads the raw responses in dir, one <job_id>.json file
+// per job. Files that can't be read or have no description are skipped with
+// a warning.
+func readJobsFromRawDir(dir string) ([]analyzer.JobInput, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no raw responses found in '%s'", dir)
+	}
+	sort.Strings(paths)
+
+	var jobs []analyzer.JobInput
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Warning: skipping raw response %s: %v\n", path, err)
+			continue
+		}
+
+		// Parsed like the scraper does, so the description is cleaned up
+		// and its language detected the same way
+		job, err := linkedin.ParseJobPosting(strings.TrimSuffix(filepath.Base(path), ".json"), data, nil)
+		if err != nil {
+			log.Printf("Warning: skipping malformed raw response %s: %v\n", path, err)
+			continue
+		}
+
+		if job.Description == "" {
+			log.Printf("Warning: skipping raw response %s without a description\n", path)
+			continue
+		}
+
+		jobs = append(jobs, analyzer.JobInput{
+			JobID:         job.JobID,
+			Description:   job.Description,
+			Language:      job.Language,
+			WorkplaceType: job.WorkplaceType,
+		})
+	}
+
+	return jobs, nil
+}
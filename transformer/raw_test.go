@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"transformer/analyzer"
+)
+
+func TestReadJobsFromRawDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"4100000001.json": `{
+			"title": "Backend Developer",
+			"description": {"text": "<p>We are looking for a Go developer to join our team.</p><ul><li>You will work on the payments &amp; billing services with the rest of the team.</li></ul>"},
+			"workplaceTypes": ["urn:li:fs_workplaceType:2"]
+		}`,
+		"4100000002.json": `{
+			"title": "Desarrollador Backend",
+			"description": {"text": "Buscamos un desarrollador para el equipo de pagos.<br>Trabajo con Go y Kubernetes en la nube de la empresa."}
+		}`,
+		"4100000003.json": `{"title": "No description", "description": {"text": "  <p></p> "}}`,
+		"4100000004.json": `{"title": `,
+		"notes.txt":       `not a raw response`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jobs, err := readJobsFromRawDir(dir)
+	if err != nil {
+		t.Fatalf("readJobsFromRawDir: %v", err)
+	}
+
+	want := []analyzer.JobInput{
+		{
+			JobID:         "4100000001",
+			Description:   "We are looking for a Go developer to join our team.\nYou will work on the payments & billing services with the rest of the team.",
+			Language:      "en",
+			WorkplaceType: "remote",
+		},
+		{
+			JobID:       "4100000002",
+			Description: "Buscamos un desarrollador para el equipo de pagos.\nTrabajo con Go y Kubernetes en la nube de la empresa.",
+			Language:    "es",
+		},
+	}
+	if !reflect.DeepEqual(jobs, want) {
+		t.Errorf("got jobs %+v, want %+v", jobs, want)
+	}
+}
+
+func TestReadJobsFromEmptyRawDir(t *testing.T) {
+	if _, err := readJobsFromRawDir(t.TempDir()); err == nil {
+		t.Error("readJobsFromRawDir of an empty directory succeeded, want an error")
+	}
+}
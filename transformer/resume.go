@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"transformer/analyzer"
+)
+
+// openResumeFile opens the ndjson output of an interrupted run at path for
+// appending, creating it when missing, and returns the analyses already in
+// it. A last line left incomplete by a crash is truncated, so its job is
+// analyzed again.
+func openResumeFile(path string) (*os.File, []analyzer.JobAnalysis, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open '%s' to resume: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("could not read '%s' to resume: %w", path, err)
+	}
+
+	var analyses []analyzer.JobAnalysis
+	complete := 0 // length of the prefix of data made of whole lines
+	for complete < len(data) {
+		end := bytes.IndexByte(data[complete:], '\n')
+		if end < 0 {
+			break
+		}
+		line := bytes.TrimSpace(data[complete : complete+end])
+		if len(line) > 0 {
+			var analysis analyzer.JobAnalysis
+			if err := json.Unmarshal(line, &analysis); err != nil {
+				f.Close()
+				return nil, nil, fmt.Errorf("could not resume from '%s', which is not a --format ndjson output: %w", path, err)
+			}
+			analyses = append(analyses, analysis)
+		}
+		complete += end + 1
+	}
+
+	if complete < len(data) {
+		log.Printf("Discarding the incomplete last line of %s.\n", path)
+		if err := f.Truncate(int64(complete)); err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("could not truncate '%s': %w", path, err)
+		}
+	}
+	if _, err := f.Seek(int64(complete), io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("could not seek '%s': %w", path, err)
+	}
+
+	return f, analyses, nil
+}
+
+// createOutputFile creates the ndjson output at path, replacing any previous
+// one, for the batches to be written to as they are processed.
+func createOutputFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create directory of '%s': %w", path, err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create '%s': %w", path, err)
+	}
+	return f, nil
+}
+
+// skipAnalyzed drops the jobs with an analysis in done, returning the
+// remaining ones and how many were dropped.
+func skipAnalyzed(jobs []analyzer.JobInput, done []analyzer.JobAnalysis) ([]analyzer.JobInput, int) {
+	analyzed := make(map[string]bool, len(done))
+	for _, analysis := range done {
+		analyzed[analysis.JobID] = true
+	}
+
+	var remaining []analyzer.JobInput
+	for _, job := range jobs {
+		if !analyzed[job.JobID] {
+			remaining = append(remaining, job)
+		}
+	}
+	return remaining, len(jobs) - len(remaining)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"transformer/analyzer"
+)
+
+// promptJobID matches the JobID lines of the prompts sent by the analyzer.
+var promptJobID = regexp.MustCompile(`(?m)^JobID: (\S+)$`)
+
+// fakeGemini is an analyzer.ContentGenerator answering every request with an
+// analysis of each job in the prompt, recording the job IDs it was sent.
+type fakeGemini struct {
+	// hangAfter, when positive, is how many requests are answered before
+	// the rest hang until their context is done.
+	hangAfter int
+	// delay, when set, returns how long to take to answer the request for
+	// jobIDs.
+	delay func(jobIDs []string) time.Duration
+	// failJobs are the jobs whose requests always fail.
+	failJobs map[string]bool
+
+	mu    sync.Mutex
+	sent  []string
+	calls int
+}
+
+func (g *fakeGemini) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	g.mu.Lock()
+	g.calls++
+	hang := g.hangAfter > 0 && g.calls > g.hangAfter
+	g.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	var analyses []analyzer.JobAnalysis
+	var jobIDs []string
+	for _, match := range promptJobID.FindAllStringSubmatch(contents[0].Parts[0].Text, -1) {
+		analyses = append(analyses, analyzer.JobAnalysis{JobID: match[1], Seniority: "Senior", Skills: []string{"go"}})
+		jobIDs = append(jobIDs, match[1])
+	}
+	if g.delay != nil {
+		time.Sleep(g.delay(jobIDs))
+	}
+	for _, jobID := range jobIDs {
+		if g.failJobs[jobID] {
+			return nil, fmt.Errorf("failing job %s", jobID)
+		}
+	}
+
+	g.mu.Lock()
+	for _, analysis := range analyses {
+		g.sent = append(g.sent, analysis.JobID)
+	}
+	g.mu.Unlock()
+
+	data, err := json.Marshal(analyses)
+	if err != nil {
+		return nil, err
+	}
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      genai.NewContentFromText(string(data), genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}, nil
+}
+
+// crashingWriter fails every write once it wrote its first batches, like a
+// run killed right after writing them.
+type crashingWriter struct {
+	ResultWriter
+	batches int
+}
+
+func (cw *crashingWriter) WriteBatch(results []analyzer.JobAnalysis) error {
+	if cw.batches == 0 {
+		return errors.New("killed")
+	}
+	cw.batches--
+	return cw.ResultWriter.WriteBatch(results)
+}
+
+func TestResumeAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "analyses.ndjson")
+	jobs := make([]analyzer.JobInput, 6)
+	for i := range jobs {
+		jobs[i] = analyzer.JobInput{JobID: fmt.Sprint(i + 1), Description: "Go developer"}
+	}
+	batches := analyzer.CreateBatches(jobs, analyzer.BatchLimits{MaxTokensPerRequest: 1000, MaxJobs: 2})
+
+	newAnalyzer := func(gemini *fakeGemini) *analyzer.Analyzer {
+		a := analyzer.New(gemini)
+		a.Backoff.After = func(time.Duration) <-chan time.Time { return time.After(0) }
+		return a
+	}
+
+	// First run, which dies right after writing its first batch
+	f, err := createOutputFile(path)
+	if err != nil {
+		t.Fatalf("createOutputFile: %v", err)
+	}
+	output, err := newResultWriter("ndjson", f, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := processBatches(context.Background(), newAnalyzer(&fakeGemini{}), batches, 1,
+		&crashingWriter{ResultWriter: output, batches: 1}, analyzer.NewVocabulary(nil))
+	if err == nil || stats.processed != 1 {
+		t.Fatalf("first run processed %d batches with error %v, want it killed after 1", stats.processed, err)
+	}
+	f.Close() // what the OS does to the killed process, without flushing anything
+
+	// Resumed run
+	f, resumed, err := openResumeFile(path)
+	if err != nil {
+		t.Fatalf("openResumeFile: %v", err)
+	}
+	remaining, skipped := skipAnalyzed(jobs, resumed)
+	if skipped != 2 {
+		t.Errorf("resuming skipped %d jobs, want the 2 of the first batch", skipped)
+	}
+
+	gemini := &fakeGemini{}
+	output, err = newResultWriter("ndjson", f, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remainingBatches := analyzer.CreateBatches(remaining, analyzer.BatchLimits{MaxTokensPerRequest: 1000, MaxJobs: 2})
+	if _, err := processBatches(context.Background(), newAnalyzer(gemini), remainingBatches, 1, output, analyzer.NewVocabulary(nil)); err != nil {
+		t.Fatalf("resumed run: %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if want := []string{"3", "4", "5", "6"}; !slices.Equal(gemini.sent, want) {
+		t.Errorf("resumed run analyzed jobs %q, want %q", gemini.sent, want)
+	}
+
+	analyses, err := readAnalyses(path)
+	if err != nil {
+		t.Fatalf("could not read the output: %v", err)
+	}
+	var ids []string
+	for _, analysis := range analyses {
+		ids = append(ids, analysis.JobID)
+	}
+	if want := []string{"1", "2", "3", "4", "5", "6"}; !slices.Equal(ids, want) {
+		t.Errorf("output holds analyses of %q, want each job once: %q", ids, want)
+	}
+}
@@ -3,262 +3,601 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math/rand"
 	"os"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"google.golang.org/genai"
+
+	"transformer/analyzer"
 )
 
 // --- Configuration Constants ---
 
-// The model to use.
-const MODEL_NAME = "gemini-2.5-flash-lite"
+// Input price in USD per million tokens of the default model, used by --estimate.
+const DEFAULT_PRICE_PER_MILLION_TOKENS = 0.10
 
-// The maximum allowed tokens per request to Gemini.
-const MAX_TOKENS_PER_REQUEST = 15000
+// Exit code of a run stopped because the Gemini quota was exhausted, so
+// scripts can tell it apart from other failures and retry the next day.
+const EXIT_QUOTA_EXHAUSTED = 3
 
-// A common ratio for estimating tokens from characters (rough estimate: 4 characters per token).
-const TOKEN_TO_CHAR_RATIO = 4
+// Exit code of a run stopped at its --deadline, after writing the analyses
+// made until then.
+const EXIT_DEADLINE_EXCEEDED = 4
 
-// Estimated overhead for the fixed system prompt and the JSON schema.
-const SYSTEM_OVERHEAD_TOKENS = 2500
+// --- Main Logic ---
 
-// --- Data Structures ---
+func main() {
+	// 1. Setup and Validation
+	modelFlag := flag.String("model", "", "Gemini model to use (default: $GEMINI_MODEL or "+analyzer.MODEL_NAME+")")
+	estimate := flag.Bool("estimate", false, "only print the estimated tokens and cost of the run, without calling the API")
+	pricePerMillion := flag.Float64("price-per-million-tokens", DEFAULT_PRICE_PER_MILLION_TOKENS, "input price in USD per million tokens, used by --estimate")
+	outputPath := flag.String("output", "", "file to write the results to (default: stdout); --format ndjson writes it per batch so --resume can continue an interrupted run, the other formats replace it only once the run completes")
+	format := flag.String("format", "json", "output format: json (a single array at the end), ndjson (one object per line, written per batch) or table (aligned columns for reading in a terminal)")
+	resume := flag.Bool("resume", false, "append to the --output of an interrupted --format ndjson run, skipping the jobs already in it, instead of replacing it")
+	compact := flag.Bool("compact", false, "write the json format without indentation, about half the size")
+	defaultBackoff := analyzer.NewBackoff()
+	retryBase := flag.Duration("retry-base", defaultBackoff.Base, "base delay of the exponential backoff between API attempts")
+	retryCap := flag.Duration("retry-cap", defaultBackoff.Cap, "maximum delay between API attempts")
+	seed := flag.Int64("seed", 0, "seed of the retry jitter, logged to reproduce a run (0 picks one from the time)")
+	deadline := flag.Duration("deadline", 0, "maximum duration of the whole run, e.g. 30m; at the deadline the requests in flight are aborted and the analyses made so far are written (0 means no deadline)")
+	workers := flag.Int("workers", 1, "how many batches are sent to Gemini concurrently, results are still written in batch order")
+	maxSplitDepth := flag.Int("max-split-depth", analyzer.MAX_SPLIT_DEPTH, "how many times a failed batch is split in halves to recover the jobs that didn't cause the failure (0 skips failed batches whole)")
+	maxJobsPerBatch := flag.Int("max-jobs-per-batch", analyzer.MAX_JOBS_PER_BATCH, "maximum number of jobs sent in a single API call (0 means only the token limit applies)")
+	maxTokens := flag.Int("max-tokens-per-request", envInt("GEMINI_MAX_TOKENS_PER_REQUEST", analyzer.MAX_TOKENS_PER_REQUEST), "token budget of a single API call (env: GEMINI_MAX_TOKENS_PER_REQUEST)")
+	overheadTokens := flag.Int("system-overhead-tokens", envInt("GEMINI_SYSTEM_OVERHEAD_TOKENS", analyzer.SYSTEM_OVERHEAD_TOKENS), "tokens of every API call taken by the system prompt and schema (env: GEMINI_SYSTEM_OVERHEAD_TOKENS)")
+	lang := flag.String("lang", "", "only analyze jobs the scraper detected to be in this language, e.g. en or es (default: all)")
+	limit := flag.Int("limit", 0, "only analyze the first N jobs read, to try prompt or schema changes on a sample (0 means all)")
+	minDescChars := flag.Int("min-desc-chars", 0, "skip jobs whose description is shorter than this many characters (0 keeps all)")
+	verbose := flag.Bool("verbose", false, "log the prompt and raw model output of every batch")
+	coverageFile := flag.String("coverage-report", "", "file to also write the JSON coverage report (jobs in, analyses out, missing job IDs) to")
+	vocabularyFile := flag.String("vocabulary", "", "file to also write the skills vocabulary (distinct skills with their counts) to, as CSV if it ends in .csv and JSON otherwise")
+	vocabularyFrom := flag.String("vocabulary-from", "", "only build the skills vocabulary of the analyses in this file (a previous run's output), written to --vocabulary or stdout")
+	dumpDir := flag.String("dump-dir", "", "directory to write the raw model output of batches that can't be parsed to, as batch-<id>.txt")
+	check := flag.Bool("check", false, "only verify that the Gemini credentials (GEMINI_API_KEY or Vertex AI) work with the model, then exit")
+	rawDir := flag.String("raw-dir", "", "directory of raw LinkedIn responses stored by the scraper's --raw-dir to analyze, besides any input file")
+	onDuplicate := flag.String("on-duplicate", "last", "which job to keep when several input files contain the same job_id: first or last")
+	analysisConfigFile := flag.String("analysis-config", "", "JSON file with the system instruction and fields to extract (default: the built-in JobAnalysis fields)")
+	seniorityLevels := flag.String("seniority-levels", "", "comma-separated seniority levels the model picks from, e.g. Junior,Senior,Staff (default: the analysis config's, "+strings.Join(analyzer.SENIORITY_LEVELS, ",")+" for the built-in one)")
+	skillAliasesFile := flag.String("skill-aliases", "", "JSON file mapping skill aliases to their canonical name, added to the built-in ones")
+	flag.Parse()
+
+	inputPaths := flag.Args()
+	if len(inputPaths) == 0 && *rawDir == "" && !*check && *vocabularyFrom == "" && stdinIsPiped() {
+		inputPaths = []string{"-"}
+	}
 
-// JobInput represents a job object in the input JSON file.
-type JobInput struct {
-	JobID       string `json:"job_id"`
-	Description string `json:"description"`
-}
+	if len(inputPaths) == 0 && *rawDir == "" && !*check && *vocabularyFrom == "" {
+		fmt.Println("Usage: go run job_analyzer.go [flags] <path/to/input.json|->...")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
 
-// JobAnalysis represents the desired structured output for a single job.
-// NOTE: Field names are intentionally lowercase to match the requested JSON schema keys.
-type JobAnalysis struct {
-	JobID              string   `json:"job_id"`
-	Seniority          string   `json:"seniority"`
-	Skills             []string `json:"skills"`
-	OnsiteHybridRemote string   `json:"onsite_hybrid_remote"`
-}
+	if *limit < 0 {
+		fmt.Printf("ERROR: invalid --limit value %d: must not be negative\n", *limit)
+		os.Exit(1)
+	}
 
-// --- Main Logic ---
+	if *resume && (*outputPath == "" || *format != "ndjson") {
+		fmt.Printf("ERROR: --resume needs --output and --format ndjson, which is written per batch\n")
+		os.Exit(1)
+	}
 
-func main() {
-	// 1. Setup and Validation
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run job_analyzer.go <path/to/input.json>")
+	if *workers < 1 {
+		fmt.Printf("ERROR: invalid --workers value %d: must be at least 1\n", *workers)
 		os.Exit(1)
 	}
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		fmt.Println("ERROR: GEMINI_API_KEY environment variable not set.")
+	if *onDuplicate != "first" && *onDuplicate != "last" {
+		fmt.Printf("ERROR: invalid --on-duplicate value '%s': must be first or last\n", *onDuplicate)
 		os.Exit(1)
 	}
 
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
-	})
-	if err != nil {
-		fmt.Printf("ERROR creating Gemini client: %v\n", err)
+	modelName := analyzer.ResolveModelName(*modelFlag)
+
+	limits := analyzer.BatchLimits{
+		MaxTokensPerRequest:  *maxTokens,
+		SystemOverheadTokens: *overheadTokens,
+		MaxJobs:              *maxJobsPerBatch,
+	}
+	if err := limits.Validate(); err != nil {
+		fmt.Printf("ERROR: invalid batch limits: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 2. Read Input File
-	inputFilePath := os.Args[1]
-	jobs, err := readJobsFromFile(inputFilePath)
+	if *check {
+		if err := checkGemini(modelName); err != nil {
+			fmt.Printf("ERROR: Gemini check failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Gemini check OK")
+		return
+	}
+
+	analysisConfig := analyzer.DefaultAnalysisConfig()
+	if *analysisConfigFile != "" {
+		config, err := analyzer.LoadAnalysisConfig(*analysisConfigFile)
+		if err != nil {
+			fmt.Printf("ERROR loading analysis config: %v\n", err)
+			os.Exit(1)
+		}
+		analysisConfig = config
+	}
+	if *seniorityLevels != "" {
+		var levels []string
+		for _, level := range strings.Split(*seniorityLevels, ",") {
+			if level = strings.TrimSpace(level); level != "" {
+				levels = append(levels, level)
+			}
+		}
+		if err := analysisConfig.SetSeniorityLevels(levels); err != nil {
+			fmt.Printf("ERROR: invalid --seniority-levels value: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	skillAliases := analyzer.DefaultSkillAliases()
+	if *skillAliasesFile != "" {
+		if err := analyzer.LoadSkillAliases(*skillAliasesFile, skillAliases); err != nil {
+			fmt.Printf("ERROR loading skill aliases: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	vocabulary := analyzer.NewVocabulary(skillAliases)
+	if *vocabularyFrom != "" {
+		analyses, err := readAnalyses(*vocabularyFrom)
+		if err != nil {
+			fmt.Printf("ERROR reading analyses: %v\n", err)
+			os.Exit(1)
+		}
+		vocabulary.Add(analyses)
+		if err := writeVocabulary(*vocabularyFile, vocabulary.Skills()); err != nil {
+			fmt.Printf("ERROR writing skills vocabulary: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var out io.Writer = os.Stdout
+	var outFile *atomicFile
+	var ndjsonFile *os.File
+	var resumed []analyzer.JobAnalysis
+	if *resume {
+		// Every batch is appended as soon as it's processed, so an
+		// interrupted run loses at most the batches in flight
+		var err error
+		ndjsonFile, resumed, err = openResumeFile(*outputPath)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		out = ndjsonFile
+	} else if *outputPath != "" && *format == "ndjson" {
+		// Written straight to the file rather than replacing it at the
+		// end, so the batches of an interrupted run can be resumed
+		var err error
+		ndjsonFile, err = createOutputFile(*outputPath)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		out = ndjsonFile
+	} else if *outputPath != "" {
+		var err error
+		outFile, err = newAtomicFile(*outputPath)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		out = outFile
+	}
+
+	output, err := newResultWriter(*format, out, *compact)
 	if err != nil {
-		fmt.Printf("ERROR reading input file: %v\n", err)
+		fmt.Printf("ERROR: %v\n", err)
 		os.Exit(1)
 	}
-	log.Printf("Successfully loaded %d job descriptions from %s.\n", len(jobs), inputFilePath)
 
-	// 3. Batching
-	batches := createBatches(jobs)
-	log.Printf("Created %d batches for API calls based on token limit.\n", len(batches))
+	// 2. Read Input Files
+	var jobs []analyzer.JobInput
+	seen := make(map[string]string)
+	for _, inputFilePath := range inputPaths {
+		fileJobs, err := readJobsFromFile(inputFilePath)
+		if err != nil {
+			fmt.Printf("ERROR reading input file: %v\n", err)
+			os.Exit(1)
+		}
+		log.Printf("Successfully loaded %d job descriptions from %s.\n", len(fileJobs), inputFilePath)
 
-	// 4. Processing Batches
-	var finalResults []JobAnalysis
-	for i, batch := range batches {
-		log.Printf("Processing batch %d/%d (containing %d jobs)...\n", i+1, len(batches), len(batch))
+		jobs = mergeJobs(jobs, fileJobs, seen, inputFilePath, *onDuplicate == "last")
+	}
 
-		batchResults, err := processBatch(ctx, client, batch)
+	if *rawDir != "" {
+		rawJobs, err := readJobsFromRawDir(*rawDir)
 		if err != nil {
-			log.Printf("ERROR processing batch %d: %v. Skipping batch.\n", i+1, err)
-			continue
+			fmt.Printf("ERROR reading raw responses: %v\n", err)
+			os.Exit(1)
 		}
+		log.Printf("Successfully loaded %d job descriptions from raw responses in %s.\n", len(rawJobs), *rawDir)
 
-		finalResults = append(finalResults, batchResults...)
+		jobs = mergeJobs(jobs, rawJobs, seen, *rawDir, *onDuplicate == "last")
 	}
 
-	// 5. Output Final Results
-	finalJSON, err := json.MarshalIndent(finalResults, "", "  ")
+	if *lang != "" {
+		jobs = filterLanguage(jobs, *lang)
+		log.Printf("Kept %d jobs in language %s.\n", len(jobs), *lang)
+	}
+
+	if *minDescChars > 0 {
+		var dropped int
+		jobs, dropped = filterShortDescriptions(jobs, *minDescChars)
+		log.Printf("Skipped %d jobs with a description shorter than %d characters.\n", dropped, *minDescChars)
+	}
+
+	if len(resumed) > 0 {
+		var skipped int
+		jobs, skipped = skipAnalyzed(jobs, resumed)
+		vocabulary.Add(resumed)
+		log.Printf("Resuming: skipped %d jobs already analyzed in %s.\n", skipped, *outputPath)
+	}
+
+	if *limit > 0 && len(jobs) > *limit {
+		jobs = limitJobs(jobs, *limit)
+		log.Printf("Limited the run to the first %d jobs.\n", *limit)
+	}
+
+	// 3. Batching
+	batches := analyzer.CreateBatches(jobs, limits)
+	log.Printf("Created %d batches for API calls based on token limit.\n", len(batches))
+
+	if *estimate {
+		printEstimate(os.Stdout, analyzer.EstimateBatches(batches, limits), *pricePerMillion)
+		return
+	}
+
+	log.Printf("Using model %s.\n", modelName)
+
+	ctx := context.Background()
+	if *deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *deadline)
+		defer cancel()
+	}
+	client, err := analyzer.NewClient(ctx)
 	if err != nil {
-		log.Printf("ERROR marshalling final results: %v\n", err)
+		fmt.Printf("ERROR: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println(string(finalJSON))
+	jobAnalyzer := analyzer.New(client.Models)
+	jobAnalyzer.Model = modelName
+	jobAnalyzer.Config = analysisConfig
+	jobAnalyzer.SkillAliases = skillAliases
+	jobAnalyzer.Backoff.Base, jobAnalyzer.Backoff.Cap = *retryBase, *retryCap
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Using random seed %d, pass --seed %d to reproduce the run.\n", *seed, *seed)
+	jobAnalyzer.Backoff.Rand = rand.New(rand.NewSource(*seed))
+	jobAnalyzer.Verbose = *verbose
+	jobAnalyzer.DumpDir = *dumpDir
+	jobAnalyzer.MaxSplitDepth = *maxSplitDepth
+
+	// 4. Processing Batches, up to --workers at a time
+	stats, writeErr := processBatches(ctx, jobAnalyzer, batches, *workers, output, vocabulary)
+	if writeErr != nil {
+		outFile.abort()
+		os.Exit(1)
+	}
+
+	// 5. Output Final Results
+	if err := output.Close(); err != nil {
+		log.Printf("ERROR writing final results: %v\n", err)
+		outFile.abort()
+		os.Exit(1)
+	}
+
+	if ndjsonFile != nil {
+		if err := ndjsonFile.Close(); err != nil {
+			log.Printf("ERROR writing final results: %v\n", err)
+			os.Exit(1)
+		}
+		if *resume {
+			log.Printf("Results appended to %s.\n", *outputPath)
+		} else {
+			log.Printf("Results written to %s.\n", *outputPath)
+		}
+	}
+
+	if outFile != nil {
+		if err := outFile.commit(); err != nil {
+			log.Printf("ERROR writing final results: %v\n", err)
+			os.Exit(1)
+		}
+		log.Printf("Results written to %s.\n", *outputPath)
+	}
+
+	coverage := analyzer.NewCoverageReport(jobs, stats.analyzedIDs)
+	log.Printf("Coverage: %d jobs in, %d analyses out, %d missing.\n", coverage.JobsIn, coverage.AnalysesOut, len(coverage.Missing))
+	if len(coverage.Missing) > 0 {
+		log.Printf("Missing job IDs: %s\n", strings.Join(coverage.Missing, ", "))
+	}
+	coverage.Blocked = jobAnalyzer.Blocked
+	for jobID, reason := range coverage.Blocked {
+		log.Printf("Job %s was blocked by Gemini (%s).\n", jobID, reason)
+	}
+	if len(coverage.Unexpected) > 0 {
+		log.Printf("Warning: analyses for job IDs not in the input: %s\n", strings.Join(coverage.Unexpected, ", "))
+	}
+	if *vocabularyFile != "" {
+		if err := writeVocabulary(*vocabularyFile, vocabulary.Skills()); err != nil {
+			log.Printf("ERROR writing skills vocabulary: %v\n", err)
+		}
+	}
+	if *coverageFile != "" {
+		if err := writeCoverageReport(*coverageFile, coverage); err != nil {
+			log.Printf("ERROR writing coverage report: %v\n", err)
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Printf("Deadline of %v reached: wrote %d analyses from %d of %d batches. Rerun the remaining jobs, e.g. with --resume.\n",
+			*deadline, stats.analyzed, stats.processed, len(batches))
+		os.Exit(EXIT_DEADLINE_EXCEEDED)
+	}
+	if stats.quotaExhausted {
+		log.Printf("Gemini quota exhausted: wrote %d analyses from %d of %d batches. Rerun the remaining jobs once the quota resets.\n",
+			stats.analyzed, stats.processed, len(batches))
+		os.Exit(EXIT_QUOTA_EXHAUSTED)
+	}
+}
+
+// runStats counts what a run of processBatches got done.
+type runStats struct {
+	processed, analyzed int
+	analyzedIDs         []string
+	quotaExhausted      bool
 }
 
-// readJobsFromFile reads the input JSON file and unmarshals it into a slice of JobInput.
-func readJobsFromFile(filePath string) ([]JobInput, error) {
-	data, err := os.ReadFile(filePath)
+// processBatches analyzes batches with up to workers of them in flight and
+// writes the analyses of every batch to output, and adds them to vocabulary,
+// in batch order as soon as the batch is processed. Failed batches are
+// skipped, and the run stops once the quota is exhausted or writing fails,
+// returning the write error. Every worker has returned by then.
+func processBatches(ctx context.Context, jobAnalyzer *analyzer.Analyzer, batches [][]analyzer.JobInput, workers int, output ResultWriter, vocabulary *analyzer.Vocabulary) (runStats, error) {
+	var stats runStats
+	var writeErr error
+	jobAnalyzer.ProcessBatches(ctx, batches, workers, func(result analyzer.BatchResult) bool {
+		batchID, i, batchResults, err := analyzer.BatchID(result.Batch), result.Index, result.Analyses, result.Err
+		if errors.Is(err, analyzer.ErrQuotaExhausted) {
+			log.Printf("[batch %s] ERROR processing batch %d: %v. Stopping the run.\n", batchID, i+1, err)
+			stats.quotaExhausted = true
+			if len(batchResults) == 0 {
+				return false
+			}
+		} else if err != nil && len(batchResults) == 0 {
+			log.Printf("[batch %s] ERROR processing batch %d: %v. Skipping batch.\n", batchID, i+1, err)
+			return true
+		} else if err != nil {
+			log.Printf("[batch %s] ERROR processing part of batch %d: %v. Keeping the %d recovered analyses.\n", batchID, i+1, err, len(batchResults))
+		}
+
+		// Batches hold consecutive input jobs, so ordering each one keeps the
+		// whole output in input order
+		analyzer.SortByInput(batchResults, result.Batch)
+
+		if err := output.WriteBatch(batchResults); err != nil {
+			log.Printf("[batch %s] ERROR writing results of batch %d: %v\n", batchID, i+1, err)
+			writeErr = err
+			return false
+		}
+		stats.processed++
+		stats.analyzed += len(batchResults)
+		for _, result := range batchResults {
+			stats.analyzedIDs = append(stats.analyzedIDs, result.JobID)
+		}
+		vocabulary.Add(batchResults)
+
+		return !stats.quotaExhausted
+	})
+
+	return stats, writeErr
+}
+
+// checkGemini counts the tokens of a trivial prompt, which needs a valid API
+// key and model but consumes no generation quota.
+func checkGemini(modelName string) error {
+	ctx := context.Background()
+	client, err := analyzer.NewClient(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var jobs []JobInput
-	if err := json.Unmarshal(data, &jobs); err != nil {
-		return nil, err
+	if _, err := client.Models.CountTokens(ctx, modelName, genai.Text("ping"), nil); err != nil {
+		return err
 	}
-	return jobs, nil
+	return nil
 }
 
-// createBatches groups jobs into batches based on a calculated maximum character limit.
-func createBatches(jobs []JobInput) [][]JobInput {
-	// Calculate the maximum characters allowed for the *input* descriptions
-	maxInputTokens := MAX_TOKENS_PER_REQUEST - SYSTEM_OVERHEAD_TOKENS
-	maxInputChars := maxInputTokens * TOKEN_TO_CHAR_RATIO
-	if maxInputChars <= 0 {
-		log.Printf("Warning: Calculated max input characters is non-positive (%d). Using a default of 4000.\n", maxInputChars)
-		maxInputChars = 4000
+// envInt returns the integer in the environment variable name, or def when it
+// is unset or not a number.
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
 	}
 
-	log.Printf("Maximum estimated input characters per request: %d (approx %d tokens).\n", maxInputChars, maxInputTokens)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: ignoring %s=%q: not a number.\n", name, value)
+		return def
+	}
+	return n
+}
 
-	var batches [][]JobInput
-	var currentBatch []JobInput
-	currentBatchCharCount := 0
+// readJobsFromFile reads the input JSON file and unmarshals it into a slice of
+// JobInput. A path of "-" reads from stdin.
+func readJobsFromFile(filePath string) ([]analyzer.JobInput, error) {
+	if filePath == "-" {
+		return readJobs(os.Stdin)
+	}
 
-	for _, job := range jobs {
-		jobCharCount := len(job.Description)
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	jobs, err := readJobs(f)
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %w", filePath, err)
+	}
+	return jobs, nil
+}
+
+// readJobs reads a JSON array of jobs from r, validated by decodeJobs.
+func readJobs(r io.Reader) ([]analyzer.JobInput, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
 
-		// If adding the current job description exceeds the limit, finalize the current batch
-		if currentBatchCharCount+jobCharCount > maxInputChars && len(currentBatch) > 0 {
-			batches = append(batches, currentBatch)
-			currentBatch = nil
-			currentBatchCharCount = 0
+	return decodeJobs(data)
+}
+
+// decodeJobs decodes data, which must be a JSON array of job objects with a
+// non-empty job_id each. Every problem found is reported together in the
+// returned error, and jobs with an empty description are logged.
+func decodeJobs(data []byte) ([]analyzer.JobInput, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("the input must be a JSON array of jobs, not a JSON %s", typeErr.Value)
 		}
+		return nil, fmt.Errorf("the input is not valid JSON: %w", err)
+	}
 
-		// Add the job to the current batch
-		currentBatch = append(currentBatch, job)
-		currentBatchCharCount += jobCharCount
+	jobs := make([]analyzer.JobInput, 0, len(entries))
+	var problems []error
+	for i, entry := range entries {
+		var job analyzer.JobInput
+		if err := json.Unmarshal(entry, &job); err != nil {
+			problems = append(problems, fmt.Errorf("job #%d is not a job object: %w", i+1, err))
+			continue
+		}
+		if strings.TrimSpace(job.JobID) == "" {
+			problems = append(problems, fmt.Errorf("job #%d has no job_id", i+1))
+			continue
+		}
+		if strings.TrimSpace(job.Description) == "" {
+			log.Printf("Warning: job %s (#%d) has an empty description.\n", job.JobID, i+1)
+		}
+		jobs = append(jobs, job)
 	}
 
-	// Add the last batch if it's not empty
-	if len(currentBatch) > 0 {
-		batches = append(batches, currentBatch)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid input, %d problems found:\n%w", len(problems), errors.Join(problems...))
 	}
+	return jobs, nil
+}
 
-	return batches
+// stdinIsPiped reports whether stdin is a pipe or file rather than a terminal.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
 }
 
-// processBatch sends a batch of job descriptions to the Gemini API and parses the array response.
-func processBatch(ctx context.Context, client *genai.Client, batchJobs []JobInput) ([]JobAnalysis, error) {
-	// 1. Construct the combined prompt
-	var promptBuilder strings.Builder
-	promptBuilder.WriteString("Analyze the following job descriptions and provide the analysis for ALL of them. The jobs are separated by '---JOBBREAK---'.\n\n")
-
-	// Append all job descriptions and their IDs
-	for i, job := range batchJobs {
-		promptBuilder.WriteString(fmt.Sprintf("JobID: %s\nDescription:\n%s\n", job.JobID, job.Description))
-		if i < len(batchJobs)-1 {
-			promptBuilder.WriteString("\n---JOBBREAK---\n\n")
+// mergeJobs appends fileJobs, read from fileName, to jobs skipping job IDs
+// already loaded. seen maps every loaded job ID to the file it came from. When
+// lastWins is true a duplicate replaces the earlier job in place instead.
+func mergeJobs(jobs, fileJobs []analyzer.JobInput, seen map[string]string, fileName string, lastWins bool) []analyzer.JobInput {
+	for _, job := range fileJobs {
+		previousFile, duplicate := seen[job.JobID]
+		if !duplicate {
+			seen[job.JobID] = fileName
+			jobs = append(jobs, job)
+			continue
 		}
-	}
 
-	// 2. Define the System Instruction
-	systemInstruction := `You are an expert job market analyst. Your task is to extract structured data from the provided job descriptions.
-You MUST return a single JSON array containing an analysis object for every job provided in the input.
-
-IMPORTANT: the answer MUST have EXACTLY ONE object per JobID.
-
-Crucial formatting rules:
-1. Ensure the "job_id" field in the output matches the "Job ID" from the input.
-2. ONLY include technical skills in the skills array.
-3. THIS IS VERY IMPORTANT:  For the skills array, each item MUST be a single, atomic, machine-readable keyword.
-   - DO NOT use full sentences, verbose explanations, or parenthetical remarks.
-   - Example (Good): "gcp", "kubernetes", "data_modeling".
-   - Example (Bad): "Experience with Cloud technologies (AWS/Azure)", "Must have 5+ years of experience in the industry".
-4. You must ONLY use information explicitly present or clearly implied by the job text. 
-	**If information for any field other than 'job_id' is NOT found, you MUST omit that field entirely** from the JSON object. 
-	For the skills array field, if no items are found, the model must return an **empty array (\[])**.
-	DO NOT make up, infer, or hallucinate any missing data. Keep all array values concise and in lowercase. 
-`
-
-	// 3. Define the JSON Schema using the SDK's schema package
-	schema := &genai.Schema{
-		Type: genai.TypeArray,
-		Items: &genai.Schema{
-			Type: genai.TypeObject,
-			Properties: map[string]*genai.Schema{
-				"job_id": {
-					Type:        genai.TypeString,
-					Description: "Job ID, must match the input Job ID.",
-				},
-				"seniority": {
-					Type:        genai.TypeString,
-					Description: "The seniority level of the job.",
-					Enum:        []string{"Junior", "Semisenior", "Senior"},
-				},
-				"skills": {
-					Type:        genai.TypeArray,
-					Description: "List of skills for the job.",
-					Items:       &genai.Schema{Type: genai.TypeString},
-				},
-				"onsite_hybrid_remote": {
-					Type:        genai.TypeString,
-					Description: "The work arrangement for the job.",
-					Enum:        []string{"on_site", "hybrid", "remote"},
-				},
-			},
-			Required: []string{"job_id", "skills"},
-		},
-	}
-
-	// 5. Call the API (SDK handles retry/backoff logic for most transient errors)
-	var resp *genai.GenerateContentResponse
-	var lastErr error
-	const maxRetries = 3
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		resp, lastErr = client.Models.GenerateContent(ctx,
-			MODEL_NAME,
-			genai.Text(promptBuilder.String()),
-			&genai.GenerateContentConfig{
-				ResponseMIMEType:  "application/json",
-				ResponseSchema:    schema,
-				SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: systemInstruction}}},
-			},
-		)
-		if lastErr == nil {
-			break // Success
+		log.Printf("Warning: job %s in %s was already loaded from %s.\n", job.JobID, fileName, previousFile)
+		if !lastWins {
+			continue
 		}
 
-		log.Printf("Attempt %d failed: %v. Retrying in %v...\n", attempt+1, lastErr, time.Second*(1<<attempt))
-		time.Sleep(time.Second * (1 << attempt)) // Exponential backoff
+		seen[job.JobID] = fileName
+		for i := range jobs {
+			if jobs[i].JobID == job.JobID {
+				jobs[i] = job
+				break
+			}
+		}
 	}
 
-	if lastErr != nil {
-		return nil, fmt.Errorf("gemini API call failed after %d attempts: %w", maxRetries, lastErr)
+	return jobs
+}
+
+// limitJobs returns the first limit jobs, or every job when limit is 0.
+func limitJobs(jobs []analyzer.JobInput, limit int) []analyzer.JobInput {
+	if limit > 0 && len(jobs) > limit {
+		return jobs[:limit]
 	}
+	return jobs
+}
 
-	// 6. Extract and Parse the JSON content
-	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("gemini API returned no candidates or content in response")
+// filterShortDescriptions returns the jobs whose description has at least
+// minChars characters, and how many were dropped.
+func filterShortDescriptions(jobs []analyzer.JobInput, minChars int) ([]analyzer.JobInput, int) {
+	var kept []analyzer.JobInput
+	for _, job := range jobs {
+		if utf8.RuneCountInString(job.Description) >= minChars {
+			kept = append(kept, job)
+		}
 	}
+	return kept, len(jobs) - len(kept)
+}
+
+// filterLanguage returns the jobs written in lang. Jobs whose language is
+// unknown are dropped too.
+func filterLanguage(jobs []analyzer.JobInput, lang string) []analyzer.JobInput {
+	var kept []analyzer.JobInput
+	for _, job := range jobs {
+		if job.Language == lang {
+			kept = append(kept, job)
+		}
+	}
+	return kept
+}
+
+// writeCoverageReport writes report as indented JSON to path.
+func writeCoverageReport(path string, report analyzer.CoverageReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
 
-	var batchAnalysis []JobAnalysis
-	if err := json.Unmarshal([]byte(resp.Text()), &batchAnalysis); err != nil {
-		// Log the problematic JSON for debugging
-		log.Printf("ERROR: Failed to unmarshal the model's JSON output. Raw output:\n%s\n", resp.Text())
-		return nil, fmt.Errorf("failed to unmarshal model's JSON output: %w", err)
+// printEstimate writes the per-batch and total token estimates and the
+// resulting input cost.
+func printEstimate(w io.Writer, estimates []analyzer.BatchEstimate, pricePerMillion float64) {
+	totalJobs, totalTokens := 0, 0
+	for i, e := range estimates {
+		fmt.Fprintf(w, "batch %d: %d jobs, %d chars, ~%d tokens\n", i+1, e.Jobs, e.Chars, e.Tokens)
+		totalJobs += e.Jobs
+		totalTokens += e.Tokens
 	}
 
-	log.Printf("Batch processed successfully. Received analysis for %d jobs.\n", len(batchAnalysis))
-	return batchAnalysis, nil
+	cost := float64(totalTokens) / 1_000_000 * pricePerMillion
+	fmt.Fprintf(w, "total: %d batches, %d jobs, ~%d input tokens, ~$%.4f at $%.2f per million tokens\n",
+		len(estimates), totalJobs, totalTokens, cost, pricePerMillion)
 }
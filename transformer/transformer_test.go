@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"transformer/analyzer"
+)
+
+func TestProcessBatchesStopsAtDeadline(t *testing.T) {
+	jobs := make([]analyzer.JobInput, 6)
+	for i := range jobs {
+		jobs[i] = analyzer.JobInput{JobID: fmt.Sprint(i + 1), Description: "Go developer"}
+	}
+	batches := analyzer.CreateBatches(jobs, analyzer.BatchLimits{MaxTokensPerRequest: 1000, MaxJobs: 2})
+
+	var out bytes.Buffer
+	output, err := newResultWriter("json", &out, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The analyzer keeps its real backoff, so a retry that would sleep past
+	// the deadline must not hold the run either
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	stats, err := processBatches(ctx, analyzer.New(&fakeGemini{hangAfter: 1}), batches, 1, output, analyzer.NewVocabulary(nil))
+	if err != nil {
+		t.Fatalf("processBatches: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run returned %v after a 200ms deadline", elapsed)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.processed != 1 || stats.analyzed != 2 {
+		t.Errorf("processed %d batches with %d analyses, want the first batch of 2", stats.processed, stats.analyzed)
+	}
+
+	var analyses []analyzer.JobAnalysis
+	if err := json.Unmarshal(out.Bytes(), &analyses); err != nil {
+		t.Fatalf("could not decode the output %q: %v", out.String(), err)
+	}
+	var ids []string
+	for _, analysis := range analyses {
+		ids = append(ids, analysis.JobID)
+	}
+	if want := []string{"1", "2"}; !slices.Equal(ids, want) {
+		t.Errorf("output holds analyses of %q, want the ones made before the deadline %q", ids, want)
+	}
+}
+
+func TestProcessBatchesKeepsInputOrder(t *testing.T) {
+	jobs := make([]analyzer.JobInput, 12)
+	for i := range jobs {
+		jobs[i] = analyzer.JobInput{JobID: fmt.Sprint(i + 1), Description: "Go developer"}
+	}
+	batches := analyzer.CreateBatches(jobs, analyzer.BatchLimits{MaxTokensPerRequest: 1000, MaxJobs: 2})
+
+	// Every batch takes a different time to answer, so they complete in a
+	// shuffled order
+	rng := rand.New(rand.NewPCG(1, 2))
+	delays := map[string]time.Duration{}
+	for i, n := range rng.Perm(len(batches)) {
+		delays[batches[i][0].JobID] = time.Duration(n) * 5 * time.Millisecond
+	}
+	gemini := &fakeGemini{delay: func(jobIDs []string) time.Duration { return delays[jobIDs[0]] }}
+
+	var out bytes.Buffer
+	output, err := newResultWriter("ndjson", &out, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := processBatches(context.Background(), analyzer.New(gemini), batches, len(batches), output, analyzer.NewVocabulary(nil)); err != nil {
+		t.Fatalf("processBatches: %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if slices.Equal(gemini.sent, analyzedIDs(t, out.Bytes())) {
+		t.Fatalf("batches completed in input order %q, want them shuffled", gemini.sent)
+	}
+	want := make([]string, len(jobs))
+	for i, job := range jobs {
+		want[i] = job.JobID
+	}
+	if ids := analyzedIDs(t, out.Bytes()); !slices.Equal(ids, want) {
+		t.Errorf("output holds analyses of %q, want input order %q", ids, want)
+	}
+}
+
+// analyzedIDs returns the job IDs of the ndjson analyses in data, in order.
+func analyzedIDs(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	var ids []string
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var analysis analyzer.JobAnalysis
+		if err := json.Unmarshal(line, &analysis); err != nil {
+			t.Fatalf("could not decode output line %q: %v", line, err)
+		}
+		ids = append(ids, analysis.JobID)
+	}
+	return ids
+}
+
+func TestCoverageReportListsSkippedBatch(t *testing.T) {
+	jobs := make([]analyzer.JobInput, 6)
+	for i := range jobs {
+		jobs[i] = analyzer.JobInput{JobID: fmt.Sprint(i + 1), Description: "Go developer"}
+	}
+	batches := analyzer.CreateBatches(jobs, analyzer.BatchLimits{MaxTokensPerRequest: 1000, MaxJobs: 2})
+
+	jobAnalyzer := analyzer.New(&fakeGemini{failJobs: map[string]bool{"3": true, "4": true}})
+	jobAnalyzer.Backoff.After = func(time.Duration) <-chan time.Time { return time.After(0) }
+	output, err := newResultWriter("ndjson", io.Discard, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := processBatches(context.Background(), jobAnalyzer, batches, 1, output, analyzer.NewVocabulary(nil))
+	if err != nil {
+		t.Fatalf("processBatches: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	if err := writeCoverageReport(path, analyzer.NewCoverageReport(jobs, stats.analyzedIDs)); err != nil {
+		t.Fatalf("writeCoverageReport: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report analyzer.CoverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("could not decode the report %q: %v", data, err)
+	}
+
+	want := analyzer.CoverageReport{JobsIn: 6, AnalysesOut: 4, Missing: []string{"3", "4"}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got coverage report %+v, want %+v", report, want)
+	}
+}
+
+func TestPrintEstimate(t *testing.T) {
+	jobs := []analyzer.JobInput{
+		{JobID: "1", Description: strings.Repeat("a", 400)},
+		{JobID: "2", Description: strings.Repeat("b", 400)},
+		{JobID: "3", Description: strings.Repeat("c", 800)},
+	}
+	limits := analyzer.BatchLimits{MaxTokensPerRequest: 400, SystemOverheadTokens: 100}
+	batches := analyzer.CreateBatches(jobs, limits)
+
+	var out bytes.Buffer
+	printEstimate(&out, analyzer.EstimateBatches(batches, limits), 2)
+
+	want := "batch 1: 2 jobs, 800 chars, ~300 tokens\n" +
+		"batch 2: 1 jobs, 800 chars, ~300 tokens\n" +
+		"total: 2 batches, 3 jobs, ~600 input tokens, ~$0.0012 at $2.00 per million tokens\n"
+	if out.String() != want {
+		t.Errorf("printEstimate wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestMergeJobsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"backend.json": `[{"job_id": "1", "description": "Go developer"}, {"job_id": "2", "description": "Backend from backend.json"}]`,
+		"data.json":    `[{"job_id": "2", "description": "Backend from data.json"}, {"job_id": "3", "description": "Spark"}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, lastWins := range []bool{false, true} {
+		var jobs []analyzer.JobInput
+		seen := make(map[string]string)
+		for _, name := range []string{"backend.json", "data.json"} {
+			fileJobs, err := readJobsFromFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("readJobsFromFile: %v", err)
+			}
+			jobs = mergeJobs(jobs, fileJobs, seen, name, lastWins)
+		}
+
+		want := []analyzer.JobInput{
+			{JobID: "1", Description: "Go developer"},
+			{JobID: "2", Description: "Backend from backend.json"},
+			{JobID: "3", Description: "Spark"},
+		}
+		if lastWins {
+			want[1].Description = "Backend from data.json"
+		}
+		if !reflect.DeepEqual(jobs, want) {
+			t.Errorf("merged jobs (last wins %v) = %+v, want %+v", lastWins, jobs, want)
+		}
+	}
+}
+
+func TestReadJobs(t *testing.T) {
+	input := `[
+		{"job_id": "1", "description": "Go developer", "category": "backend", "search_term": "golang"},
+		{"job_id": "2", "description": "Spark", "language": "en"}
+	]`
+	jobs, err := readJobs(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readJobs: %v", err)
+	}
+
+	want := []analyzer.JobInput{
+		{JobID: "1", Description: "Go developer", Category: "backend", SearchTerm: "golang"},
+		{JobID: "2", Description: "Spark", Language: "en"},
+	}
+	if !reflect.DeepEqual(jobs, want) {
+		t.Errorf("readJobs = %+v, want %+v", jobs, want)
+	}
+}
+
+func TestCheckGemini(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"authorized", http.StatusOK, false},
+		{"unauthorized", http.StatusUnauthorized, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					fmt.Fprint(w, `{"totalTokens": 1}`)
+					return
+				}
+				fmt.Fprintf(w, `{"error": {"code": %d, "message": "API key not valid", "status": "UNAUTHENTICATED"}}`, tt.status)
+			}))
+			defer server.Close()
+
+			t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "")
+			t.Setenv("GEMINI_API_KEY", "test-key")
+			t.Setenv("GOOGLE_GEMINI_BASE_URL", server.URL)
+
+			err := checkGemini("test-model")
+			if !strings.HasSuffix(path, "/models/test-model:countTokens") {
+				t.Errorf("got request to %q, want the countTokens endpoint of test-model", path)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLimitJobsBatchesOnlyLimit(t *testing.T) {
+	var jobs []analyzer.JobInput
+	for i := range 10 {
+		jobs = append(jobs, analyzer.JobInput{JobID: fmt.Sprint(i), Description: "Go developer"})
+	}
+
+	batches := analyzer.CreateBatches(limitJobs(jobs, 3), analyzer.DefaultBatchLimits())
+
+	var batched []string
+	for _, batch := range batches {
+		for _, job := range batch {
+			batched = append(batched, job.JobID)
+		}
+	}
+	if want := []string{"0", "1", "2"}; !reflect.DeepEqual(batched, want) {
+		t.Errorf("got batched jobs %v, want %v", batched, want)
+	}
+
+	if got := limitJobs(jobs, 0); len(got) != len(jobs) {
+		t.Errorf("got %d jobs with no limit, want %d", len(got), len(jobs))
+	}
+}
+
+func TestFilterShortDescriptions(t *testing.T) {
+	jobs := []analyzer.JobInput{
+		{JobID: "1", Description: "Go"},
+		{JobID: "2", Description: "Señor Go"},
+		{JobID: "3", Description: "Senior Go developer"},
+	}
+
+	kept, dropped := filterShortDescriptions(jobs, 8)
+
+	var ids []string
+	for _, job := range kept {
+		ids = append(ids, job.JobID)
+	}
+	if want := []string{"2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got jobs %v, want %v", ids, want)
+	}
+	if dropped != 1 {
+		t.Errorf("got %d dropped, want 1", dropped)
+	}
+}
+
+func TestDecodeJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantIDs []string
+		wantErr string
+	}{
+		{"valid", `[{"job_id": "1", "description": "Go"}, {"job_id": "2", "description": ""}]`, []string{"1", "2"}, ""},
+		{"missing job_id", `[{"job_id": "1", "description": "Go"}, {"description": "SQL"}]`, nil, "job #2 has no job_id"},
+		{"not an array", `{"job_id": "1"}`, nil, "must be a JSON array of jobs, not a JSON object"},
+		{"not JSON", `[{"job_id": "1"`, nil, "not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs, err := decodeJobs([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeJobs: %v", err)
+			}
+			var ids []string
+			for _, job := range jobs {
+				ids = append(ids, job.JobID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("got jobs %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestSearchContextRoundTrip(t *testing.T) {
+	jobs, err := readJobs(strings.NewReader(`[
+		{"job_id": "1", "description": "Go developer", "category": "backend", "search_term": "golang"},
+		{"job_id": "2", "description": "Spark developer", "category": "data", "search_term": "spark"},
+		{"job_id": "3", "description": "React developer"}
+	]`))
+	if err != nil {
+		t.Fatalf("readJobs: %v", err)
+	}
+	batches := analyzer.CreateBatches(jobs, analyzer.BatchLimits{MaxTokensPerRequest: 1000, MaxJobs: 2})
+
+	var out bytes.Buffer
+	output, err := newResultWriter("ndjson", &out, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := processBatches(context.Background(), analyzer.New(&fakeGemini{}), batches, 1, output, analyzer.NewVocabulary(nil)); err != nil {
+		t.Fatalf("processBatches: %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][2]string
+	for _, line := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		var analysis analyzer.JobAnalysis
+		if err := json.Unmarshal(line, &analysis); err != nil {
+			t.Fatalf("could not decode output line %q: %v", line, err)
+		}
+		got = append(got, [2]string{analysis.Category, analysis.SearchTerm})
+	}
+	want := [][2]string{{"backend", "golang"}, {"data", "spark"}, {"", ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got search context %q, want %q", got, want)
+	}
+}
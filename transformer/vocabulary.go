@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"transformer/analyzer"
+)
+
+// readAnalyses reads the analyses written by a previous run, either as a
+// JSON array (--format json) or one object per line (--format ndjson).
+func readAnalyses(path string) ([]analyzer.JobAnalysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var analyses []analyzer.JobAnalysis
+		if err := json.Unmarshal(trimmed, &analyses); err != nil {
+			return nil, fmt.Errorf("could not decode analyses in '%s': %w", path, err)
+		}
+		return analyses, nil
+	}
+
+	var analyses []analyzer.JobAnalysis
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var analysis analyzer.JobAnalysis
+		if err := dec.Decode(&analysis); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("could not decode analyses in '%s': %w", path, err)
+		}
+		analyses = append(analyses, analysis)
+	}
+	return analyses, nil
+}
+
+// writeVocabulary writes skills to path as CSV when it has a .csv extension
+// and as a JSON array otherwise. An empty path writes JSON to stdout.
+func writeVocabulary(path string, skills []analyzer.SkillCount) error {
+	if path == "" {
+		return encodeVocabularyJSON(os.Stdout, skills)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = encodeVocabularyCSV(w, skills)
+	} else {
+		err = encodeVocabularyJSON(w, skills)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func encodeVocabularyJSON(w io.Writer, skills []analyzer.SkillCount) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(skills)
+}
+
+func encodeVocabularyCSV(w io.Writer, skills []analyzer.SkillCount) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"skill", "count"}); err != nil {
+		return err
+	}
+	for _, s := range skills {
+		if err := cw.Write([]string{s.Skill, strconv.Itoa(s.Count)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
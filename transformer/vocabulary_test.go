@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"transformer/analyzer"
+)
+
+func TestWriteVocabularyFromAnalyses(t *testing.T) {
+	dir := t.TempDir()
+	analysesFile := filepath.Join(dir, "analyses.ndjson")
+	ndjson := `{"job_id": "1", "skills": ["Go", "SQL"]}
+{"job_id": "2", "skills": ["golang", "Docker"]}
+`
+	if err := os.WriteFile(analysesFile, []byte(ndjson), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyses, err := readAnalyses(analysesFile)
+	if err != nil {
+		t.Fatalf("readAnalyses: %v", err)
+	}
+	vocabulary := analyzer.NewVocabulary(map[string]string{"golang": "go"})
+	vocabulary.Add(analyses)
+
+	csvFile := filepath.Join(dir, "vocabulary.csv")
+	if err := writeVocabulary(csvFile, vocabulary.Skills()); err != nil {
+		t.Fatalf("writeVocabulary: %v", err)
+	}
+
+	got, err := os.ReadFile(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "skill,count\ngo,2\ndocker,1\nsql,1\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}